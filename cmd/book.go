@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// printBook writes everything about one book as text
+func printBook(out io.Writer, book pkg.BookDetail) {
+	fmt.Fprintf(out, "%s - %s\n", book.Title, book.Author)
+	fmt.Fprintf(out, "ID\t\t\t: %s\n", book.BookID)
+
+	if book.ISBN != "" {
+		fmt.Fprintf(out, "ISBN\t\t\t: %s\n", book.ISBN)
+	}
+
+	if book.Words > 0 {
+		fmt.Fprintf(out, "Words\t\t\t: %s\n", pkg.HumanizeInt(book.Words))
+	}
+
+	if book.Rating > 0 {
+		fmt.Fprintf(out, "Rating\t\t\t: %d/5\n", book.Rating)
+	}
+
+	status := "not started"
+	switch {
+	case book.IsFinished && book.ReadCount > 1:
+		status = fmt.Sprintf("finished (read %d times)", book.ReadCount)
+	case book.IsFinished:
+		status = "finished"
+	case len(book.Reads) > 0:
+		status = "in progress"
+	}
+
+	fmt.Fprintf(out, "Status\t\t\t: %s\n", status)
+
+	if len(book.Shelves) > 0 {
+		fmt.Fprintf(out, "Shelves\t\t\t: %s\n", strings.Join(book.Shelves, ", "))
+	}
+
+	if book.FirstRead != "" {
+		fmt.Fprintf(out, "First read\t\t: %s\n", formatTime(book.FirstRead))
+		fmt.Fprintf(out, "Last read\t\t: %s\n", formatTime(book.LastRead))
+	}
+
+	readDuration := time.Duration(book.ReadSeconds()) * time.Second
+	fmt.Fprintf(out, "Time reading\t\t: %s over %d sessions (hours: %s)\n",
+		pkg.HumanizeDuration(readDuration), len(book.Sessions), pkg.SecondsToHoursString(book.ReadSeconds()))
+
+	if len(book.ReadThroughs) > 0 {
+		fmt.Fprintln(out, "\nRead-throughs:")
+
+		for idx, readThrough := range book.ReadThroughs {
+			finished := "not finished"
+			if readThrough.IsFinished {
+				finished = "finished " + formatTime(readThrough.FinishedTime) + " (" + string(readThrough.FinishedSource) + ")"
+			}
+
+			fmt.Fprintf(out, "\t%d: started %s, %s, %s over %d sessions\n", idx+1, formatTime(readThrough.Start), finished,
+				time.Duration(readThrough.ReadSeconds())*time.Second, len(readThrough.Reads))
+		}
+	}
+
+	if len(book.Milestones) > 0 {
+		fmt.Fprintln(out, "\nMilestones:")
+
+		for _, milestone := range book.Milestones {
+			name := milestone.Name
+			if name == pkg.FinishEvent.String() {
+				name = "Finished"
+			}
+
+			fmt.Fprintf(out, "\t%s %s", formatTime(milestone.Time), name)
+			if milestone.Device != "" {
+				fmt.Fprintf(out, " on %s", milestone.Device)
+			}
+
+			if milestone.Source.IsEstimated() {
+				fmt.Fprint(out, " (estimated)")
+			}
+
+			fmt.Fprintln(out)
+		}
+	}
+
+	if len(book.Sessions) > 0 {
+		fmt.Fprintln(out, "\nSessions:")
+
+		for _, session := range book.Sessions {
+			fmt.Fprintf(out, "\tAt %s for %s", formatTime(session.Time), time.Duration(session.Duration)*time.Second)
+			if session.Device != "" {
+				fmt.Fprintf(out, " on %s", session.Device)
+			}
+
+			if session.Gap > 0 {
+				fmt.Fprintf(out, " (%s after the previous)", pkg.HumanizeDurationShort(time.Duration(session.Gap)*time.Second))
+			}
+
+			fmt.Fprintln(out)
+		}
+
+		fmt.Fprintln(out, "\nDaily reading:")
+
+		for _, day := range book.Days {
+			if day.Seconds == 0 {
+				continue
+			}
+
+			// One # per 5 minutes
+			fmt.Fprintf(out, "\t%s %8s %s\n", day.Date, time.Duration(day.Seconds)*time.Second,
+				strings.Repeat("#", max(day.Seconds/300, 1)))
+		}
+	}
+
+	if len(book.Bookmarks) > 0 {
+		fmt.Fprintln(out, "\nHighlights and notes:")
+
+		for _, bookmark := range book.Bookmarks {
+			fmt.Fprintf(out, "\t%s %s: %s\n", formatTime(bookmark.Created), bookmark.Type, bookmark.Text)
+			if bookmark.Annotation != "" {
+				fmt.Fprintf(out, "\t\tnote: %s\n", bookmark.Annotation)
+			}
+		}
+	}
+}
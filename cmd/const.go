@@ -1,6 +1,11 @@
 package cmd
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
 
 const (
 	defaultEmpty = ""
@@ -13,6 +18,8 @@ const (
 	usageYear = "Year to generate stats for (default this year)"
 
 	usageShowSessions = "Show reading sessions"
+
+	usageOverlap = "Overlapping sessions from different devices policy pick (longest), union or flag (default pick)"
 )
 
 var defaultYear int
@@ -20,3 +27,17 @@ var defaultYear int
 func init() {
 	defaultYear = time.Now().Year()
 }
+
+// statsOptions returns the stats options for the overlap policy flag
+func statsOptions(overlapPolicy string) (pkg.StatsOptions, error) {
+	options := pkg.DefaultStatsOptions
+
+	policy, err := pkg.ParseOverlapPolicy(overlapPolicy)
+	if err != nil {
+		return options, fmt.Errorf("--overlap: %w", err)
+	}
+
+	options.OverlapPolicy = policy
+
+	return options, nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Edit command adds manual corrections of synced content to local storage, the corrections survive future syncs
+func Edit(out io.Writer) int {
+	const (
+		usageContent       = "Content ID or title to correct (required)"
+		usageTitle         = "Corrected title"
+		usageAuthor        = "Corrected author"
+		usageFinished      = "Mark finished on the date e.g. 2024-01-05"
+		usageUnfinished    = "Mark not finished"
+		usageDeleteSession = "Delete the reading session that started at the time e.g. \"2024-01-02 20:15\" (can be repeated)"
+		usageHide          = "Hide the content from stats"
+		usageUnhide        = "Show hidden content again"
+		usageReset         = "Remove all corrections of the content"
+	)
+
+	var (
+		storageFn      string
+		query          string
+		title          string
+		author         string
+		finishedStr    string
+		unfinished     bool
+		deleteSessions []string
+		hide           bool
+		unhide         bool
+		reset          bool
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&query, "id", defaultEmpty, usageContent)
+	flag.StringVar(&query, "i", defaultEmpty, usageContent)
+
+	flag.StringVar(&title, "title", defaultEmpty, usageTitle)
+	flag.StringVar(&title, "t", defaultEmpty, usageTitle)
+
+	flag.StringVar(&author, "author", defaultEmpty, usageAuthor)
+	flag.StringVar(&author, "a", defaultEmpty, usageAuthor)
+
+	flag.StringVar(&finishedStr, "finished", defaultEmpty, usageFinished)
+	flag.BoolVar(&unfinished, "unfinished", false, usageUnfinished)
+
+	flag.Func("delete-session", usageDeleteSession, func(s string) error {
+		deleteSessions = append(deleteSessions, s)
+		return nil
+	})
+
+	flag.BoolVar(&hide, "hide", false, usageHide)
+	flag.BoolVar(&unhide, "unhide", false, usageUnhide)
+	flag.BoolVar(&reset, "reset", false, usageReset)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if query == "" {
+		fmt.Fprintln(out, "-i or --id content ID or title is required.")
+		return 1
+	}
+
+	if finishedStr != "" && unfinished {
+		fmt.Fprintln(out, "--finished and --unfinished cannot be used together.")
+		return 1
+	}
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	content, err := pkg.FindContent(storage.Contents(), query)
+	if err != nil {
+		fmt.Fprintf(out, "Error editing: %v\n", err)
+		return 1
+	}
+
+	correction := storage.Corrections()[content.ID]
+	if reset {
+		correction = pkg.StorageCorrection{}
+	}
+
+	if title != "" {
+		correction.Title = title
+	}
+
+	if author != "" {
+		correction.Author = author
+	}
+
+	if finishedStr != "" {
+		finishedTime, err := pkg.ParseManualTime(finishedStr)
+		if err != nil {
+			fmt.Fprintf(out, "--finished: %v\n", err)
+			return 1
+		}
+
+		finished := true
+		correction.Finished = &finished
+		correction.FinishedTime = finishedTime.Format(pkg.StorageTimeFmt)
+	}
+
+	if unfinished {
+		finished := false
+		correction.Finished = &finished
+		correction.FinishedTime = ""
+	}
+
+	for _, sessionStr := range deleteSessions {
+		sessionTime, err := pkg.ParseManualTime(sessionStr)
+		if err != nil {
+			fmt.Fprintf(out, "--delete-session: %v\n", err)
+			return 1
+		}
+
+		matches := pkg.MatchSessions(storage, content.ID, sessionTime)
+		if len(matches) == 0 {
+			fmt.Fprintf(out, "No reading session of %s started at %s\n", content.Title, sessionStr)
+			return 1
+		}
+
+		for _, match := range matches {
+			if !slices.Contains(correction.DeletedSessions, match) {
+				correction.DeletedSessions = append(correction.DeletedSessions, match)
+			}
+		}
+	}
+
+	if hide {
+		correction.Hidden = true
+	}
+
+	if unhide {
+		correction.Hidden = false
+	}
+
+	storage.SetCorrection(content.ID, correction)
+
+	if err := storage.Save(); err != nil {
+		fmt.Fprintf(out, "Error saving: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Corrections of %s - %s (%s): %s\n", content.Title, content.Author, content.ID, formatCorrection(correction))
+
+	return 0
+}
+
+// formatCorrection returns a one line summary of the correction
+func formatCorrection(correction pkg.StorageCorrection) string {
+	parts := make([]string, 0)
+
+	if correction.Title != "" {
+		parts = append(parts, fmt.Sprintf("title %q", correction.Title))
+	}
+
+	if correction.Author != "" {
+		parts = append(parts, fmt.Sprintf("author %q", correction.Author))
+	}
+
+	if correction.Finished != nil {
+		switch {
+		case !*correction.Finished:
+			parts = append(parts, "not finished")
+		case correction.FinishedTime != "":
+			parts = append(parts, "finished "+formatTime(correction.FinishedTime))
+		default:
+			parts = append(parts, "finished")
+		}
+	}
+
+	for _, session := range correction.DeletedSessions {
+		parts = append(parts, "deleted session "+formatTime(session))
+	}
+
+	if correction.Hidden {
+		parts = append(parts, "hidden")
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Export command writes local storage in another format e.g. a Goodreads import csv
+func Export(out io.Writer) int {
+	const (
+		usageOutput = "Path to write the export to (default stdout)"
+		usageFormat = "Format of the sessions, contents, bookmarks and shelves tables csv or ndjson"
+		usageFrom   = "Only sessions, bookmarks and calendar events from this date e.g. 2024-01-01"
+		usageTo     = "Only sessions, bookmarks and calendar events until this date (inclusive) e.g. 2024-12-31"
+	)
+
+	var (
+		storageFn     string
+		outputFn      string
+		format        string
+		fromStr       string
+		toStr         string
+		overlapPolicy string
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&outputFn, "output", defaultEmpty, usageOutput)
+	flag.StringVar(&outputFn, "o", defaultEmpty, usageOutput)
+
+	flag.StringVar(&format, "format", "csv", usageFormat)
+	flag.StringVar(&fromStr, "from", defaultEmpty, usageFrom)
+	flag.StringVar(&toStr, "to", defaultEmpty, usageTo)
+
+	flag.StringVar(&overlapPolicy, "overlap", defaultEmpty, usageOverlap)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s export <%s>:\n", os.Args[0], strings.Join(pkg.ExportTargets(), "|"))
+
+		flag.PrintDefaults()
+	}
+
+	// The target is the first argument e.g. export goodreads -o goodreads_import.csv
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		flag.Usage()
+		return 1
+	}
+
+	target, err := pkg.ParseExportTarget(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	_ = flag.CommandLine.Parse(os.Args[2:])
+
+	options := pkg.ExportOptions{Format: format}
+
+	if options.Stats, err = statsOptions(overlapPolicy); err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	if fromStr != "" {
+		if options.From, err = pkg.ParseManualTime(fromStr); err != nil {
+			fmt.Fprintf(out, "Error parsing from: %v\n", err)
+			return 1
+		}
+	}
+
+	if toStr != "" {
+		if options.To, err = pkg.ParseManualTimeEnd(toStr); err != nil {
+			fmt.Fprintf(out, "Error parsing to: %v\n", err)
+			return 1
+		}
+	}
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	w := out
+	if outputFn != "" {
+		fp, err := os.Create(outputFn)
+		if err != nil {
+			fmt.Fprintf(out, "Error creating output: %v\n", err)
+			return 1
+		}
+
+		defer func() {
+			_ = fp.Close()
+		}()
+
+		w = fp
+	}
+
+	if err := pkg.Export(storage, target, w, options); err != nil {
+		fmt.Fprintf(out, "Error exporting: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
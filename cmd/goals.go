@@ -14,14 +14,19 @@ import (
 )
 
 func Goals(out io.Writer) int {
-	const ()
+	const usageMode = "Mode json or text (default text)"
 
 	var (
-		storageFn    string
-		year         int
-		showSessions bool
+		storageFn     string
+		mode          string
+		year          int
+		showSessions  bool
+		overlapPolicy string
 	)
 
+	flag.StringVar(&mode, "mode", defaultEmpty, usageMode)
+	flag.StringVar(&mode, "m", defaultEmpty, usageMode)
+
 	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
 	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
 
@@ -30,6 +35,8 @@ func Goals(out io.Writer) int {
 
 	flag.BoolVar(&showSessions, "showsessions", false, usageShowSessions)
 
+	flag.StringVar(&overlapPolicy, "overlap", defaultEmpty, usageOverlap)
+
 	flag.Usage = func() {
 		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
 
@@ -47,7 +54,17 @@ func Goals(out io.Writer) int {
 		panic(err)
 	}
 
-	stats := pkg.NewStats(storage)
+	options, err := statsOptions(overlapPolicy)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	stats := pkg.NewStatsWithOptions(storage, options)
+
+	if strings.ToLower(mode) == "json" {
+		return printReport(out, stats, year)
+	}
 
 	hoursPerWeek := make([]float32, 0)
 	totalBooks := 0
@@ -75,19 +92,19 @@ func Goals(out io.Writer) int {
 
 		weekTotalSeconds := weekBookSeconds + weekArticleSeconds
 
-		fmt.Printf("Week %02d (%s) - Total %s hours  \t- Books %d in %s hours  \t- Articles %d in %s hours\n",
+		fmt.Fprintf(out, "Week %02d (%s) - Total %s hours  \t- Books %d in %s hours  \t- Articles %d in %s hours\n",
 			week, weekStartTime.Format("2006-01-02"), pkg.SecondsToHoursString(weekTotalSeconds),
 			weekBooksRead, pkg.SecondsToHoursString(weekBookSeconds),
 			weekArticleRead, pkg.SecondsToHoursString(weekArticleSeconds))
 
 		if showSessions {
-			fmt.Println("|    |Monday|Tuesda|Wednes|Thursd|Friday|Saturd|Sunday|        |")
+			fmt.Fprintln(out, "|    |Monday|Tuesda|Wednes|Thursd|Friday|Saturd|Sunday|        |")
 
 			for idx := 0; idx < 24; idx++ {
-				fmt.Println(readsInWeekToTableLine(stats, weekStartTime, idx))
+				fmt.Fprintln(out, readsInWeekToTableLine(stats, weekStartTime, idx))
 			}
 
-			fmt.Println(readsInWeekDayLine(stats, year, week))
+			fmt.Fprintln(out, readsInWeekDayLine(stats, year, week))
 		}
 
 		if weekStartTime.Unix() > time.Now().Unix() {
@@ -97,11 +114,11 @@ func Goals(out io.Writer) int {
 		hoursPerWeek = append(hoursPerWeek, float32(weekTotalSeconds)/3600.0)
 	}
 
-	fmt.Println("---")
-	fmt.Printf("Totals!\t\t     - Hours %s  \t\t- %d Books in %s hours   \t- %d Articles in %s hours",
+	fmt.Fprintln(out, "---")
+	fmt.Fprintf(out, "Totals!\t\t     - Hours %s  \t\t- %d Books in %s hours   \t- %d Articles in %s hours",
 		pkg.SecondsToHoursString(totalBookSeconds+totalArticleSeconds),
 		totalBooks, pkg.SecondsToHoursString(totalBookSeconds), totalArticles, pkg.SecondsToHoursString(totalArticleSeconds))
-	fmt.Printf("\t- Weekly average hours: %.02f\n", calculateAverage(hoursPerWeek))
+	fmt.Fprintf(out, "\t- Weekly average hours: %.02f\n", calculateAverage(hoursPerWeek))
 
 	return 0
 }
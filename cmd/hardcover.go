@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timchurchard/kobo-readstat/internal"
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Hardcover command adds the reading dates and finished books from local storage to hardcover.app
+func Hardcover(out io.Writer) int {
+	const (
+		defaultCache = "./hardcover_cache.json"
+
+		usageConfig = "Path to the config file with the API token {\"token\": \"...\"} (or set " + pkg.HardcoverTokenEnv + ")"
+		usageCache  = "Path to the cache of matched hardcover.app books default: " + defaultCache
+		usageDryRun = "Show what would be changed on hardcover.app without changing anything"
+	)
+
+	var (
+		storageFn string
+		configFn  string
+		cacheFn   string
+		dryRun    bool
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&configFn, "config", pkg.DefaultHardcoverConfigPath(), usageConfig)
+	flag.StringVar(&configFn, "c", pkg.DefaultHardcoverConfigPath(), usageConfig)
+
+	flag.StringVar(&cacheFn, "cache", defaultCache, usageCache)
+
+	flag.BoolVar(&dryRun, "dry-run", false, usageDryRun)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	config, err := pkg.LoadHardcoverConfig(configFn)
+	if err != nil {
+		fmt.Fprintf(out, "Error reading config: %v\n", err)
+		return 1
+	}
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	cache, err := pkg.OpenHardcoverCache(cacheFn)
+	if err != nil {
+		fmt.Fprintf(out, "Error reading cache: %v\n", err)
+		return 1
+	}
+
+	client := internal.NewHardcoverClient(config.Endpoint, config.Token)
+
+	result, err := pkg.HardcoverSync(storage, client, cache, dryRun)
+
+	// Keep the books matched before any error
+	if !dryRun {
+		if saveErr := cache.Save(); saveErr != nil {
+			fmt.Fprintf(out, "Error saving cache: %v\n", saveErr)
+		}
+	}
+
+	updated := "Updated"
+	if dryRun {
+		updated = "Would update"
+	}
+
+	for _, title := range result.Updated {
+		fmt.Fprintf(out, "%s: %s\n", updated, title)
+	}
+
+	for _, title := range result.Unmatched {
+		fmt.Fprintf(out, "Not found on hardcover.app: %s\n", title)
+	}
+
+	if err != nil {
+		fmt.Fprintf(out, "Error updating hardcover.app: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "%s: %d, up to date: %d, not found: %d\n", updated, len(result.Updated), result.UpToDate, len(result.Unmatched))
+
+	return 0
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// History command lists the syncs recorded in local storage
+func History(out io.Writer) int {
+	var storageFn string
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range storage.Syncs() {
+		fmt.Fprintf(out, "Sync %d at %s from %s (%s sha256:%s) - contents: %d, events: %d, bookmarks: %d\n",
+			entry.ID, formatTime(entry.Time), entry.Device, entry.Database, shortHash(entry.Hash),
+			len(entry.Contents), len(entry.Events), len(entry.Bookmarks))
+	}
+
+	return 0
+}
+
+func shortHash(hash string) string {
+	const shortHashLen = 12
+
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
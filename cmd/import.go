@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Import command adds the reading history exported from another tracker e.g. Goodreads to local storage
+func Import(out io.Writer) int {
+	const (
+		usageFile    = "Path to the export file e.g. goodreads_library_export.csv"
+		usageMapping = "Path to the json column mapping of a csv import e.g. {\"title\": \"Book\", \"start\": \"Date\", \"duration\": \"Minutes\"}"
+		usageFormat  = "Format of a csv import csv or ndjson (default from the file extension)"
+	)
+
+	var (
+		storageFn string
+		fn        string
+		mappingFn string
+		format    string
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&fn, "file", defaultEmpty, usageFile)
+	flag.StringVar(&fn, "f", defaultEmpty, usageFile)
+
+	flag.StringVar(&mappingFn, "mapping", defaultEmpty, usageMapping)
+	flag.StringVar(&mappingFn, "m", defaultEmpty, usageMapping)
+
+	flag.StringVar(&format, "format", defaultEmpty, usageFormat)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s import <%s>:\n", os.Args[0], strings.Join(pkg.ImportSources(), "|"))
+
+		flag.PrintDefaults()
+	}
+
+	// The source is the first argument e.g. import goodreads -f goodreads_library_export.csv
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		flag.Usage()
+		return 1
+	}
+
+	source, err := pkg.ParseImportSource(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	_ = flag.CommandLine.Parse(os.Args[2:])
+
+	if fn == "" && flag.NArg() > 0 {
+		fn = flag.Arg(0)
+	}
+
+	if fn == "" {
+		fmt.Fprintln(out, "-f or --file export file is required.")
+		return 1
+	}
+
+	options := pkg.ImportOptions{Format: format}
+
+	if source == pkg.ImportCSV {
+		if mappingFn == "" {
+			fmt.Fprintln(out, "-m or --mapping column mapping file is required.")
+			return 1
+		}
+
+		if options.Mapping, err = pkg.LoadImportMapping(mappingFn); err != nil {
+			fmt.Fprintf(out, "Error reading mapping: %v\n", err)
+			return 1
+		}
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := pkg.ImportFile(storage, source, fn, options)
+	if err != nil {
+		fmt.Fprintf(out, "Error importing: %v\n", err)
+		return 1
+	}
+
+	if err := storage.Save(); err != nil {
+		fmt.Fprintf(out, "Error saving: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Imported %s from %s - contents: %d, finishes: %d, shelves: %d, bookmarks: %d\n",
+		source, fn, result.Contents, result.Finishes, result.Shelves, result.Bookmarks)
+
+	for _, rejection := range result.Rejected {
+		fmt.Fprintf(out, "Rejected row %d: %s\n", rejection.Row, rejection.Reason)
+	}
+
+	return 0
+}
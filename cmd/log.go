@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Log command adds a manual reading entry e.g. a paper book or audiobook to local storage
+func Log(out io.Writer) int {
+	const (
+		usageTitle    = "Title (required)"
+		usageAuthor   = "Author"
+		usageWords    = "Number of words (optional)"
+		usagePages    = "Number of pages, used to estimate words (optional)"
+		usageStart    = "Session start time e.g. 2024-01-02 20:15"
+		usageEnd      = "Session end time e.g. 2024-01-02 21:00"
+		usageDuration = "Session duration e.g. 45m (start defaults to end, or now, minus duration)"
+		usageFinished = "Finished date or time e.g. 2024-01-05"
+		usageDevice   = "Pseudo-device name (default " + pkg.ManualDevice + ")"
+	)
+
+	var (
+		storageFn   string
+		title       string
+		author      string
+		words       int
+		pages       int
+		startStr    string
+		endStr      string
+		duration    time.Duration
+		finishedStr string
+		device      string
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&title, "title", defaultEmpty, usageTitle)
+	flag.StringVar(&title, "t", defaultEmpty, usageTitle)
+
+	flag.StringVar(&author, "author", defaultEmpty, usageAuthor)
+	flag.StringVar(&author, "a", defaultEmpty, usageAuthor)
+
+	flag.IntVar(&words, "words", 0, usageWords)
+	flag.IntVar(&pages, "pages", 0, usagePages)
+
+	flag.StringVar(&startStr, "start", defaultEmpty, usageStart)
+	flag.StringVar(&endStr, "end", defaultEmpty, usageEnd)
+	flag.DurationVar(&duration, "duration", 0, usageDuration)
+
+	flag.StringVar(&finishedStr, "finished", defaultEmpty, usageFinished)
+	flag.StringVar(&finishedStr, "f", defaultEmpty, usageFinished)
+
+	flag.StringVar(&device, "device", pkg.ManualDevice, usageDevice)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	entry := pkg.ManualEntry{
+		Title:  title,
+		Author: author,
+		Words:  words,
+		Pages:  pages,
+		Device: device,
+	}
+
+	session, err := manualSession(startStr, endStr, duration)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	if session != nil {
+		entry.Sessions = append(entry.Sessions, *session)
+	}
+
+	if finishedStr != "" {
+		entry.Finished, err = pkg.ParseManualTime(finishedStr)
+		if err != nil {
+			fmt.Fprintf(out, "--finished: %v\n", err)
+			return 1
+		}
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	cid, err := pkg.AddManualEntry(storage, entry)
+	if err != nil {
+		fmt.Fprintf(out, "Error logging: %v\n", err)
+		return 1
+	}
+
+	if err := storage.Save(); err != nil {
+		fmt.Fprintf(out, "Error saving: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Logged %s - %s (%s)\n", title, author, cid)
+
+	return 0
+}
+
+// manualSession returns the session from start and end or duration, nil if none given
+func manualSession(startStr, endStr string, duration time.Duration) (*pkg.ManualSession, error) {
+	if startStr == "" && endStr == "" && duration == 0 {
+		return nil, nil
+	}
+
+	if startStr == "" {
+		if duration == 0 {
+			return nil, fmt.Errorf("--duration is required with --end and no --start")
+		}
+
+		if endStr == "" {
+			return &pkg.ManualSession{Start: time.Now().Add(-duration), Duration: duration}, nil
+		}
+
+		end, err := pkg.ParseManualTime(endStr)
+		if err != nil {
+			return nil, fmt.Errorf("--end: %w", err)
+		}
+
+		return &pkg.ManualSession{Start: end.Add(-duration), Duration: duration}, nil
+	}
+
+	start, err := pkg.ParseManualTime(startStr)
+	if err != nil {
+		return nil, fmt.Errorf("--start: %w", err)
+	}
+
+	if endStr != "" {
+		end, err := pkg.ParseManualTime(endStr)
+		if err != nil {
+			return nil, fmt.Errorf("--end: %w", err)
+		}
+
+		duration = end.Sub(start)
+	}
+
+	return &pkg.ManualSession{Start: start, Duration: duration}, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+func Test_manualSession(t *testing.T) {
+	start := time.Date(2024, 1, 2, 20, 15, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		startStr string
+		endStr   string
+		duration time.Duration
+		expected *pkg.ManualSession
+		wantErr  bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "start and end",
+			startStr: "2024-01-02 20:15",
+			endStr:   "2024-01-02 21:00",
+			expected: &pkg.ManualSession{Start: start, Duration: 45 * time.Minute},
+		},
+		{
+			name:     "start and duration",
+			startStr: "2024-01-02 20:15",
+			duration: 45 * time.Minute,
+			expected: &pkg.ManualSession{Start: start, Duration: 45 * time.Minute},
+		},
+		{
+			name:     "end and duration",
+			endStr:   "2024-01-02 21:00",
+			duration: 45 * time.Minute,
+			expected: &pkg.ManualSession{Start: start, Duration: 45 * time.Minute},
+		},
+		{
+			name:    "end without duration",
+			endStr:  "2024-01-02 21:00",
+			wantErr: true,
+		},
+		{
+			name:     "invalid end",
+			endStr:   "yesterday",
+			duration: 45 * time.Minute,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := manualSession(tt.startStr, tt.endStr, tt.duration)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("duration only is until now", func(t *testing.T) {
+		actual, err := manualSession("", "", 45*time.Minute)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(-45*time.Minute), actual.Start, time.Minute)
+	})
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Merge command combines other local storage files into local storage
+func Merge(out io.Writer) int {
+	var storageFn string
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s: [options] other_readstat.json...\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(out, "at least one storage file to merge is required.")
+		return 1
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, otherFn := range flag.Args() {
+		if _, err := os.Stat(otherFn); err != nil {
+			panic(fmt.Sprintf("storage not found: %v", err))
+		}
+
+		other, err := pkg.OpenStorageOrCreate(otherFn)
+		if err != nil {
+			panic(err)
+		}
+
+		conflicts, err := pkg.MergeStorage(storage, other)
+		if err != nil {
+			fmt.Fprintf(out, "Error merging %s: %v\n", otherFn, err)
+			return 1
+		}
+
+		fmt.Fprintf(out, "Merged %s with %d conflicts\n", otherFn, len(conflicts))
+		for _, conflict := range conflicts {
+			fmt.Fprintf(out, "\tconflict %s %s: kept %q, %s has %q\n", conflict.ContentID, conflict.Field, conflict.Kept, otherFn, conflict.Other)
+		}
+	}
+
+	if err := storage.Save(); err != nil {
+		fmt.Fprintf(out, "Error saving: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Serve command serves the dashboard and json API of local storage, reloading it when the file changes
+func Serve(out io.Writer) int {
+	const (
+		defaultAddr = "127.0.0.1:8080"
+
+		usageAddr = "Address to listen on default: " + defaultAddr
+	)
+
+	var (
+		storageFn     string
+		addr          string
+		overlapPolicy string
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&addr, "addr", defaultAddr, usageAddr)
+	flag.StringVar(&addr, "a", defaultAddr, usageAddr)
+
+	flag.StringVar(&overlapPolicy, "overlap", defaultEmpty, usageOverlap)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	options, err := statsOptions(overlapPolicy)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	server, err := pkg.NewServer(storageFn, options)
+	if err != nil {
+		panic(err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(out, "Serving %s on http://%s/\n", storageFn, addr)
+
+	if err := httpServer.ListenAndServe(); err != nil {
+		fmt.Fprintf(out, "Error serving: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
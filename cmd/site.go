@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Site command writes a static website of every year, book, author and shelf from local storage
+func Site(out io.Writer) int {
+	const (
+		defaultSiteDir = "./site"
+
+		usageOutDir = "Directory to write the site to default: " + defaultSiteDir
+	)
+
+	var (
+		storageFn     string
+		outDir        string
+		overlapPolicy string
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.StringVar(&outDir, "out", defaultSiteDir, usageOutDir)
+	flag.StringVar(&outDir, "o", defaultSiteDir, usageOutDir)
+
+	flag.StringVar(&overlapPolicy, "overlap", defaultEmpty, usageOverlap)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	options, err := statsOptions(overlapPolicy)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	result, err := pkg.WriteSite(storage, pkg.NewStatsWithOptions(storage, options), outDir)
+	if err != nil {
+		fmt.Fprintf(out, "Error writing site: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Site %s: %d pages, %d files written, %d removed\n", outDir, result.Pages, result.Written, result.Removed)
+
+	return 0
+}
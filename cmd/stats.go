@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -14,14 +15,17 @@ import (
 // Stats command reads local storage and produces stats
 func Stats(out io.Writer) int {
 	const (
-		usageMode    = "Mode html or text (default text)"
-		usageOutPath = "Path to output file (required for mode html)"
+		usageMode     = "Mode html, json, markdown or text (default text)"
+		usageOutPath  = "Path to output file (required for mode html)"
+		usageTemplate = "Path to a text/template or html/template (.html) to write the stats with, see docs/Report-template.md"
 
 		usageShowBooks     = "Show book title and details"
 		usageShowArticles  = "Show pocket article title and details"
 		usageHideArticles  = "Hide pocket articles even time spent"
 		usageShowBookEnds  = "Show book started and finished reading"
-		usageShowBookmarks = "Show book annotations, notes and highlights"
+		usageShowBookmarks = "Show book annotations, notes and highlights (text and markdown)"
+		usageShowOverlaps  = "Show overlapping sessions from different devices and how they were resolved"
+		usageBook          = "Show everything about one book by content ID or title search (text, json or html)"
 	)
 
 	var (
@@ -29,12 +33,16 @@ func Stats(out io.Writer) int {
 		mode          string
 		year          int
 		outFn         string
+		templateFn    string
+		bookQuery     string
 		showBooks     bool
 		showArticles  bool
 		hideArticles  bool
 		showSessions  bool
 		showBookEnds  bool
 		showBookmarks bool
+		showOverlaps  bool
+		overlapPolicy string
 	)
 
 	flag.StringVar(&mode, "mode", defaultEmpty, usageMode)
@@ -46,6 +54,12 @@ func Stats(out io.Writer) int {
 	flag.StringVar(&outFn, "out", defaultEmpty, usageOutPath)
 	flag.StringVar(&outFn, "o", defaultEmpty, usageOutPath)
 
+	flag.StringVar(&templateFn, "template", defaultEmpty, usageTemplate)
+	flag.StringVar(&templateFn, "t", defaultEmpty, usageTemplate)
+
+	flag.StringVar(&bookQuery, "book", defaultEmpty, usageBook)
+	flag.StringVar(&bookQuery, "b", defaultEmpty, usageBook)
+
 	flag.IntVar(&year, "year", defaultYear, usageYear)
 	flag.IntVar(&year, "y", defaultYear, usageYear)
 
@@ -57,6 +71,9 @@ func Stats(out io.Writer) int {
 	flag.BoolVar(&showSessions, "showsessions", false, usageShowSessions)
 	flag.BoolVar(&showBookEnds, "showbookends", true, usageShowBookEnds)
 	flag.BoolVar(&showBookmarks, "showbookmarks", false, usageShowBookmarks)
+	flag.BoolVar(&showOverlaps, "showoverlaps", false, usageShowOverlaps)
+
+	flag.StringVar(&overlapPolicy, "overlap", defaultEmpty, usageOverlap)
 
 	flag.Usage = func() {
 		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
@@ -75,7 +92,13 @@ func Stats(out io.Writer) int {
 		panic(err)
 	}
 
-	stats := pkg.NewStats(storage)
+	options, err := statsOptions(overlapPolicy)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	stats := pkg.NewStatsWithOptions(storage, options)
 
 	booksReadSeconds := stats.BooksSecondsReadYear(year)
 	booksReadDuration, _ := time.ParseDuration(fmt.Sprintf("%ds", booksReadSeconds))
@@ -85,6 +108,14 @@ func Stats(out io.Writer) int {
 	totalReadSeconds := booksReadSeconds + articlesReadSeconds
 	totalReadDuration, _ := time.ParseDuration(fmt.Sprintf("%ds", totalReadSeconds))
 
+	if bookQuery != "" {
+		return statsBook(out, storage, stats, bookQuery, mode, outFn)
+	}
+
+	if templateFn != "" {
+		return printTemplate(out, storage, stats, year, templateFn, outFn)
+	}
+
 	switch strings.ToLower(mode) {
 	case "html":
 		if outFn == "" {
@@ -97,25 +128,36 @@ func Stats(out io.Writer) int {
 			panic(err)
 		}
 
+	case "json":
+		return printReport(out, stats, year)
+
+	case "markdown", "md":
+		markdownOptions := pkg.MarkdownOptions{Highlights: showBookmarks, Articles: !hideArticles}
+
+		if err := pkg.WriteMarkdownReport(out, pkg.NewReport(stats, year), markdownOptions); err != nil {
+			fmt.Fprintf(out, "Error writing report: %v\n", err)
+			return 1
+		}
+
 	case "text":
 		fallthrough
 	default:
-		fmt.Printf("Year: %d\n", year)
-		fmt.Printf("Finished books\t\t\t: %d\n", len(stats.BooksFinishedYear(year)))
+		fmt.Fprintf(out, "Year: %d\n", year)
+		fmt.Fprintf(out, "Finished books\t\t\t: %d\n", len(stats.BooksFinishedYear(year)))
 		if !hideArticles {
-			fmt.Printf("Finished articles\t\t: %d\n", len(stats.ArticlesFinishedYear(year)))
+			fmt.Fprintf(out, "Finished articles\t\t: %d\n", len(stats.ArticlesFinishedYear(year)))
 		}
 
-		fmt.Printf("Time reading books\t\t: %s (hours: %s)\n", pkg.HumanizeDuration(booksReadDuration), pkg.SecondsToHoursString(booksReadSeconds))
+		fmt.Fprintf(out, "Time reading books\t\t: %s (hours: %s)\n", pkg.HumanizeDuration(booksReadDuration), pkg.SecondsToHoursString(booksReadSeconds))
 		if !hideArticles {
-			fmt.Printf("Time reading articles\t\t: %s (hours: %s)\n", pkg.HumanizeDuration(articlesReadDuration), pkg.SecondsToHoursString(articlesReadSeconds))
+			fmt.Fprintf(out, "Time reading articles\t\t: %s (hours: %s)\n", pkg.HumanizeDuration(articlesReadDuration), pkg.SecondsToHoursString(articlesReadSeconds))
 		}
 
 		if !hideArticles {
-			fmt.Printf("Total time reading\t\t: %s (hours: %s)\n", pkg.HumanizeDuration(totalReadDuration), pkg.SecondsToHoursString(totalReadSeconds))
+			fmt.Fprintf(out, "Total time reading\t\t: %s (hours: %s)\n", pkg.HumanizeDuration(totalReadDuration), pkg.SecondsToHoursString(totalReadSeconds))
 		}
 
-		fmt.Println("\n----------")
+		fmt.Fprintln(out, "\n----------")
 
 		months := []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
 		for idx := 1; idx <= 12; idx++ {
@@ -124,12 +166,12 @@ func Stats(out io.Writer) int {
 			finishedStartedBooks := map[string]bool{}
 
 			if !hideArticles {
-				fmt.Printf("\n%s %d - Finished books: %d, articles: %d, time spend reading books: %s (hours: %s) and articles: %s (hours: %s)\n",
+				fmt.Fprintf(out, "\n%s %d - Finished books: %d, articles: %d, time spend reading books: %s (hours: %s) and articles: %s (hours: %s)\n",
 					months[idx], year, len(stats.BooksFinishedMonth(year, idx)), len(stats.ArticlesFinishedMonth(year, idx)),
 					pkg.HumanizeDuration(monthBookReadDuration), pkg.SecondsToHoursString(stats.BooksSecondsReadMonth(year, idx)),
 					pkg.HumanizeDuration(monthArticleReadDuration), pkg.SecondsToHoursString(stats.ArticlesSecondsReadMonth(year, idx)))
 			} else {
-				fmt.Printf("\n%s %d - Finished books: %d, time spend reading books: %s (hours: %s)\n",
+				fmt.Fprintf(out, "\n%s %d - Finished books: %d, time spend reading books: %s (hours: %s)\n",
 					months[idx], year, len(stats.BooksFinishedMonth(year, idx)),
 					pkg.HumanizeDuration(monthBookReadDuration), pkg.SecondsToHoursString(stats.BooksSecondsReadMonth(year, idx)))
 			}
@@ -139,24 +181,24 @@ func Stats(out io.Writer) int {
 					finishedStartedBooks[finishedBook.BookID] = true
 
 					duration := time.Duration(finishedBook.ReadSeconds()) * time.Second
-					fmt.Printf("\t finished book: %s - %s (Duration: %s over %d Sessions)", finishedBook.Title, finishedBook.Author, duration, finishedBook.NumSessions())
+					fmt.Fprintf(out, "\t finished book: %s - %s%s (Duration: %s over %d Sessions)", finishedBook.Title, finishedBook.Author, readThroughLabel(finishedBook), duration, finishedBook.NumSessions())
 
 					if showBookEnds {
-						fmt.Printf(" Started: %s Finished: %s\n", formatTime(finishedBook.FirstReadTime()), formatTime(finishedBook.FinishedTime))
+						fmt.Fprintf(out, " Started: %s Finished: %s\n", formatTime(finishedBook.FirstReadTime()), formatFinishedTime(finishedBook))
 					} else {
-						fmt.Printf("\n")
+						fmt.Fprintf(out, "\n")
 					}
 
 					if showSessions {
 						for jdx := range finishedBook.Reads {
 							duration = time.Duration(finishedBook.Reads[jdx].Duration) * time.Second
-							fmt.Printf("\t\tAt %s for %s\n", finishedBook.Reads[jdx].Time, duration)
+							fmt.Fprintf(out, "\t\tAt %s for %s\n", finishedBook.Reads[jdx].Time, duration)
 						}
 					}
 
 					if showBookmarks {
 						for jdx := range finishedBook.Bookmarks {
-							fmt.Printf("\t\t%s: %s\n", finishedBook.Bookmarks[jdx].Type, finishedBook.Bookmarks[jdx].Text)
+							fmt.Fprintf(out, "\t\t%s: %s\n", finishedBook.Bookmarks[jdx].Type, finishedBook.Bookmarks[jdx].Text)
 						}
 					}
 				}
@@ -169,7 +211,8 @@ func Stats(out io.Writer) int {
 							continue
 						}
 
-						if book.IsFinished {
+						readThrough, _ := book.ReadThroughInMonth(year, idx)
+						if readThrough.IsFinished {
 							continue
 						}
 
@@ -178,28 +221,28 @@ func Stats(out io.Writer) int {
 						duration := time.Duration(readSeconds) * time.Second
 						readSessions := book.NumSessionsInMonth(year, idx)
 
-						if firstReadTime, _ := time.Parse(pkg.StorageTimeFmt, book.FirstReadTime()); idx == int(firstReadTime.Month()) {
-							fmt.Printf("\t started book: %s - %s (Duration: %s over %d Sessions)", book.Title, book.Author, duration, readSessions)
+						if startTime, _ := time.Parse(pkg.StorageTimeFmt, readThrough.Start); year == startTime.Year() && idx == int(startTime.Month()) {
+							fmt.Fprintf(out, "\t started book: %s - %s (Duration: %s over %d Sessions)", book.Title, book.Author, duration, readSessions)
 						} else {
-							fmt.Printf("\t continued book: %s - %s (Duration: %s over %d Sessions)", book.Title, book.Author, duration, readSessions)
+							fmt.Fprintf(out, "\t continued book: %s - %s (Duration: %s over %d Sessions)", book.Title, book.Author, duration, readSessions)
 						}
 
 						if showBookEnds {
-							fmt.Printf(" Started: %s\n", formatTime(book.FirstReadTime()))
+							fmt.Fprintf(out, " Started: %s\n", formatTime(readThrough.Start))
 						} else {
-							fmt.Printf("\n")
+							fmt.Fprintf(out, "\n")
 						}
 
 						if showSessions {
 							for jdx := range book.Reads {
 								duration = time.Duration(book.Reads[jdx].Duration) * time.Second
-								fmt.Printf("\t\tAt %s for %s\n", book.Reads[jdx].Time, duration)
+								fmt.Fprintf(out, "\t\tAt %s for %s\n", book.Reads[jdx].Time, duration)
 							}
 						}
 
 						if showBookmarks {
 							for jdx := range book.Bookmarks {
-								fmt.Printf("\t\t%s: %s\n", book.Bookmarks[jdx].Type, book.Bookmarks[jdx].Text)
+								fmt.Fprintf(out, "\t\t%s: %s\n", book.Bookmarks[jdx].Type, book.Bookmarks[jdx].Text)
 							}
 						}
 					}
@@ -208,16 +251,139 @@ func Stats(out io.Writer) int {
 
 			if showArticles && !hideArticles {
 				for _, finishedArticle := range stats.ArticlesFinishedMonth(year, idx) {
-					fmt.Printf("\t finished article: %s - %s (%s) Finished: %s\n",
+					fmt.Fprintf(out, "\t finished article: %s - %s (%s) Finished: %s\n",
 						finishedArticle.Title, finishedArticle.Author, finishedArticle.URL, finishedArticle.FinishedTime)
 				}
 			}
 		}
+
+		if showOverlaps {
+			printOverlaps(out, stats, year)
+		}
+	}
+
+	return 0
+}
+
+func printOverlaps(out io.Writer, stats pkg.Stats, year int) {
+	overlaps := make([]pkg.StatsOverlap, 0)
+
+	for _, overlap := range stats.Overlaps {
+		if readTime, _ := time.Parse(pkg.StorageTimeFmt, overlap.Reads[0].Time); readTime.Year() == year {
+			overlaps = append(overlaps, overlap)
+		}
+	}
+
+	fmt.Fprintln(out, "\n----------")
+	fmt.Fprintf(out, "\nOverlapping sessions from different devices: %d\n", len(overlaps))
+
+	for _, overlap := range overlaps {
+		fmt.Fprintf(out, "\t %s (%s): %s => %s\n", overlap.Title, overlap.Resolution, formatReads(overlap.Reads), formatReads(overlap.Kept))
+	}
+}
+
+// printReport writes the json report of the year
+func printReport(out io.Writer, stats pkg.Stats, year int) int {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(pkg.NewReport(stats, year)); err != nil {
+		fmt.Fprintf(out, "Error writing report: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// statsBook writes the detail of the book as text, json or html
+func statsBook(out io.Writer, storage pkg.Storage, stats pkg.Stats, query, mode, outFn string) int {
+	book, err := pkg.FindBook(storage, stats, query)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	switch strings.ToLower(mode) {
+	case "html":
+		if outFn == "" {
+			fmt.Fprintf(out, "--out -o is required for mode html\n")
+			return 1
+		}
+
+		if err := pkg.NewBookChart(book, outFn); err != nil {
+			fmt.Fprintf(out, "Error writing book: %v\n", err)
+			return 1
+		}
+
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(book); err != nil {
+			fmt.Fprintf(out, "Error writing book: %v\n", err)
+			return 1
+		}
+
+	default:
+		printBook(out, book)
 	}
 
 	return 0
 }
 
+// printTemplate writes the stats of the year with the template to the out file, or out
+func printTemplate(out io.Writer, storage pkg.Storage, stats pkg.Stats, year int, templateFn, outFn string) int {
+	w := out
+	if outFn != "" {
+		fp, err := os.Create(outFn)
+		if err != nil {
+			fmt.Fprintf(out, "Error creating output: %v\n", err)
+			return 1
+		}
+
+		defer func() {
+			_ = fp.Close()
+		}()
+
+		w = fp
+	}
+
+	if err := pkg.WriteTemplateReport(w, templateFn, pkg.NewTemplateData(storage, stats, year)); err != nil {
+		fmt.Fprintf(out, "Error writing template: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func formatReads(reads []pkg.StatsRead) string {
+	result := make([]string, len(reads))
+
+	for idx := range reads {
+		result[idx] = fmt.Sprintf("%s at %s for %s", reads[idx].Device, formatTime(reads[idx].Time), time.Duration(reads[idx].Duration)*time.Second)
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// readThroughLabel marks the finished entries of re-read books e.g. " (read 2)"
+func readThroughLabel(book *pkg.StatsBook) string {
+	if book.ReadThrough > 1 {
+		return fmt.Sprintf(" (read %d)", book.ReadThrough)
+	}
+
+	return ""
+}
+
+// formatFinishedTime marks finish times that are estimated e.g. "2023-01-02 20:00:00 (estimated)"
+func formatFinishedTime(book *pkg.StatsBook) string {
+	if book.FinishedSource.IsEstimated() {
+		return formatTime(book.FinishedTime) + " (estimated)"
+	}
+
+	return formatTime(book.FinishedTime)
+}
+
 func formatTime(ts string) string {
 	return strings.Replace(strings.Replace(ts, "T", " ", 1), ".000", "", 1)
 }
@@ -1,19 +1,29 @@
 package cmd
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/timchurchard/kobo-readstat/pkg"
 )
 
 // Sync command reads a Kobo database and creates/updates local storage
 func Sync(out io.Writer) int {
+	const (
+		usageDryRun = "Show what the sync would change without saving"
+		usageMode   = "Dry run output mode text or json (default text)"
+	)
+
 	var (
 		databaseFn string
 		storageFn  string
+		dryRun     bool
+		mode       string
 	)
 
 	flag.StringVar(&databaseFn, "database", defaultEmpty, usageDatabasePath)
@@ -22,6 +32,11 @@ func Sync(out io.Writer) int {
 	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
 	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
 
+	flag.BoolVar(&dryRun, "dry-run", false, usageDryRun)
+
+	flag.StringVar(&mode, "mode", defaultEmpty, usageMode)
+	flag.StringVar(&mode, "m", defaultEmpty, usageMode)
+
 	flag.Usage = func() {
 		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
 
@@ -49,6 +64,11 @@ func Sync(out io.Writer) int {
 		panic(err)
 	}
 
+	if dryRun {
+		// Sync into a view of the storage that is never saved
+		storage = pkg.NewCopyOnWriteStorage(storage)
+	}
+
 	defer func() {
 		if err := storage.Save(); err != nil {
 			fmt.Printf("Error saving: %v\n", err)
@@ -62,5 +82,55 @@ func Sync(out io.Writer) int {
 		return 1
 	}
 
+	if dryRun {
+		syncs := storage.Syncs()
+		diff := pkg.NewSyncDiff(storage, syncs[len(syncs)-1])
+
+		if strings.ToLower(mode) == "json" {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+
+			if err := enc.Encode(diff); err != nil {
+				fmt.Fprintf(out, "Error encoding: %v\n", err)
+				return 1
+			}
+
+			return 0
+		}
+
+		printSyncDiff(out, diff)
+	}
+
 	return 0
 }
+
+func printSyncDiff(out io.Writer, diff pkg.SyncDiff) {
+	fmt.Fprintf(out, "Dry run of sync from %s (%s sha256:%s), nothing saved\n", diff.Device, diff.Database, shortHash(diff.Hash))
+
+	fmt.Fprintf(out, "\nNew books: %d\n", len(diff.NewBooks))
+	for _, content := range diff.NewBooks {
+		fmt.Fprintf(out, "\t%s - %s (%s)\n", content.Title, content.Author, content.ID)
+	}
+
+	fmt.Fprintf(out, "\nNew articles: %d\n", len(diff.NewArticles))
+
+	fmt.Fprintf(out, "\nNewly finished: %d\n", len(diff.NewlyFinished))
+	for _, content := range diff.NewlyFinished {
+		fmt.Fprintf(out, "\t%s - %s (%s)\n", content.Title, content.Author, content.ID)
+	}
+
+	fmt.Fprintf(out, "\nChanged titles: %d\n", len(diff.ChangedTitles))
+	for _, changed := range diff.ChangedTitles {
+		fmt.Fprintf(out, "\t%s - %s => %s - %s (%s)\n", changed.PreviousTitle, changed.PreviousAuthor, changed.Title, changed.Author, changed.ID)
+	}
+
+	fmt.Fprintf(out, "\nAdded sessions: %d (Duration: %s)\n", len(diff.AddedSessions), time.Duration(diff.SessionSeconds())*time.Second)
+	for _, session := range diff.AddedSessions {
+		fmt.Fprintf(out, "\t%s at %s for %s\n", session.Title, formatTime(session.Time), time.Duration(session.Duration)*time.Second)
+	}
+
+	fmt.Fprintf(out, "\nNew bookmarks: %d\n", len(diff.NewBookmarks))
+	for _, bookmark := range diff.NewBookmarks {
+		fmt.Fprintf(out, "\t%s %s: %s\n", bookmark.Title, bookmark.Type, bookmark.Text)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timchurchard/kobo-readstat/pkg"
+)
+
+// Undo command removes everything a sync added to local storage
+func Undo(out io.Writer) int {
+	const usageSyncID = "Sync ID to undo (see history command)"
+
+	var (
+		storageFn string
+		syncID    int
+	)
+
+	flag.StringVar(&storageFn, "storage", defaultStorage, usageStoragePath)
+	flag.StringVar(&storageFn, "s", defaultStorage, usageStoragePath)
+
+	flag.IntVar(&syncID, "id", 0, usageSyncID)
+	flag.IntVar(&syncID, "i", 0, usageSyncID)
+
+	flag.Usage = func() {
+		fmt.Fprintf(out, "Usage of %s %s:\n", os.Args[0], os.Args[1])
+
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if syncID == 0 {
+		fmt.Fprintln(out, "-i or --id sync ID is required.")
+		return 1
+	}
+
+	if _, err := os.Stat(storageFn); err != nil {
+		panic(fmt.Sprintf("storage not found: %v", err))
+	}
+
+	storage, err := pkg.OpenStorageOrCreate(storageFn)
+	if err != nil {
+		panic(err)
+	}
+
+	entry, err := storage.UndoSync(syncID)
+	if err != nil {
+		fmt.Fprintf(out, "Error undoing: %v\n", err)
+		return 1
+	}
+
+	if err := storage.Save(); err != nil {
+		fmt.Fprintf(out, "Error saving: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Undone sync %d from %s at %s - contents: %d, events: %d, bookmarks: %d\n",
+		entry.ID, entry.Device, formatTime(entry.Time), len(entry.Contents), len(entry.Events), len(entry.Bookmarks))
+
+	return 0
+}
@@ -1 +1,306 @@
 package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A minimal client of the hardcover.app GraphQL API, see docs/Hardcover-app.md
+//
+// https://hardcover.app/account/api
+// https://api.hardcover.app/v1/graphql
+
+const HardcoverEndpoint = "https://api.hardcover.app/v1/graphql"
+
+// Hardcover user book statuses
+const (
+	HardcoverStatusWantToRead       = 1
+	HardcoverStatusCurrentlyReading = 2
+	HardcoverStatusRead             = 3
+)
+
+// HardcoverReadingFormatEbook is the reading format of dates read on a Kobo
+const HardcoverReadingFormatEbook = 4
+
+var ErrHardcover = errors.New("hardcover error")
+
+type HardcoverClient struct {
+	Endpoint string
+	Token    string
+
+	HTTPClient *http.Client
+}
+
+type HardcoverBook struct {
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Authors []string `json:"authors"`
+}
+
+type HardcoverUserBook struct {
+	ID       int                     `json:"id"`
+	StatusID int                     `json:"status_id"`
+	Reads    []HardcoverUserBookRead `json:"user_book_reads"`
+}
+
+type HardcoverUserBookRead struct {
+	ID         int     `json:"id"`
+	StartedAt  *string `json:"started_at"`
+	FinishedAt *string `json:"finished_at"`
+}
+
+// HardcoverDatesRead is one read to insert (ID nil) or update, dates are 2006-01-02
+type HardcoverDatesRead struct {
+	ID              *int    `json:"id"`
+	Action          string  `json:"action"`
+	StartedAt       *string `json:"started_at"`
+	FinishedAt      *string `json:"finished_at"`
+	ReadingFormatID int     `json:"reading_format_id"`
+}
+
+// hardcoverBookResult is a book with contributions as returned by the API
+type hardcoverBookResult struct {
+	ID            int    `json:"id"`
+	Title         string `json:"title"`
+	Contributions []struct {
+		Author struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"contributions"`
+}
+
+func (b hardcoverBookResult) book() HardcoverBook {
+	result := HardcoverBook{ID: b.ID, Title: b.Title, Authors: make([]string, 0, len(b.Contributions))}
+
+	for _, contribution := range b.Contributions {
+		result.Authors = append(result.Authors, contribution.Author.Name)
+	}
+
+	return result
+}
+
+const hardcoverBookFields = `id title contributions { author { name } }`
+
+// NewHardcoverClient returns a client for the endpoint (default HardcoverEndpoint) authenticated with the API token
+func NewHardcoverClient(endpoint, token string) *HardcoverClient {
+	if endpoint == "" {
+		endpoint = HardcoverEndpoint
+	}
+
+	return &HardcoverClient{
+		Endpoint:   endpoint,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// FindBookByISBN returns the book of the edition with the ISBN-13, nil if not found
+func (c *HardcoverClient) FindBookByISBN(isbn string) (*HardcoverBook, error) {
+	const query = `query FindBookByISBN($isbn: String!) {
+  editions(where: {isbn_13: {_eq: $isbn}}, limit: 1) {
+    book { ` + hardcoverBookFields + ` }
+  }
+}`
+
+	var result struct {
+		Editions []struct {
+			Book hardcoverBookResult `json:"book"`
+		} `json:"editions"`
+	}
+
+	if err := c.query("FindBookByISBN", query, map[string]any{"isbn": isbn}, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Editions) == 0 {
+		return nil, nil
+	}
+
+	book := result.Editions[0].Book.book()
+
+	return &book, nil
+}
+
+// FindBooksByTitle returns the books with the exact title
+func (c *HardcoverClient) FindBooksByTitle(title string) ([]HardcoverBook, error) {
+	const query = `query FindBookByTitle($title: String!) {
+  books(where: {title: {_eq: $title}}, limit: 5) { ` + hardcoverBookFields + ` }
+}`
+
+	var result struct {
+		Books []hardcoverBookResult `json:"books"`
+	}
+
+	if err := c.query("FindBookByTitle", query, map[string]any{"title": title}, &result); err != nil {
+		return nil, err
+	}
+
+	books := make([]HardcoverBook, 0, len(result.Books))
+	for _, book := range result.Books {
+		books = append(books, book.book())
+	}
+
+	return books, nil
+}
+
+// UserBook returns the user book of the book with its dates read, nil if the book is not in the library
+func (c *HardcoverClient) UserBook(bookID int) (*HardcoverUserBook, error) {
+	const query = `query UserBook($bookId: Int!) {
+  me {
+    user_books(where: {book_id: {_eq: $bookId}}) { id status_id user_book_reads { id started_at finished_at } }
+  }
+}`
+
+	var result struct {
+		Me []struct {
+			UserBooks []HardcoverUserBook `json:"user_books"`
+		} `json:"me"`
+	}
+
+	if err := c.query("UserBook", query, map[string]any{"bookId": bookID}, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Me) == 0 || len(result.Me[0].UserBooks) == 0 {
+		return nil, nil
+	}
+
+	return &result.Me[0].UserBooks[0], nil
+}
+
+// InsertUserBook adds the book to the library with the status and returns the user book ID
+func (c *HardcoverClient) InsertUserBook(bookID, statusID int) (int, error) {
+	const query = `mutation InsertUserBook($bookId: Int!, $statusId: Int!) {
+  insert_user_book(object: {book_id: $bookId, status_id: $statusId}) { id error }
+}`
+
+	var result struct {
+		InsertUserBook hardcoverMutationResult `json:"insert_user_book"`
+	}
+
+	if err := c.query("InsertUserBook", query, map[string]any{"bookId": bookID, "statusId": statusID}, &result); err != nil {
+		return 0, err
+	}
+
+	return result.InsertUserBook.ID, result.InsertUserBook.err()
+}
+
+// UpdateUserBookStatus changes the status of the user book e.g. to HardcoverStatusRead
+func (c *HardcoverClient) UpdateUserBookStatus(userBookID, statusID int) error {
+	const query = `mutation UpdateUserBook($id: Int!, $statusId: Int!) {
+  update_user_book(id: $id, object: {status_id: $statusId}) { id error }
+}`
+
+	var result struct {
+		UpdateUserBook hardcoverMutationResult `json:"update_user_book"`
+	}
+
+	if err := c.query("UpdateUserBook", query, map[string]any{"id": userBookID, "statusId": statusID}, &result); err != nil {
+		return err
+	}
+
+	return result.UpdateUserBook.err()
+}
+
+// UpsertDatesRead inserts or updates the dates read of the user book
+func (c *HardcoverClient) UpsertDatesRead(userBookID int, datesRead []HardcoverDatesRead) error {
+	const query = `mutation UpsertDatesRead($userBookId: Int!, $datesRead: [DatesReadInput]!) {
+  upsert_user_book_reads(user_book_id: $userBookId, datesRead: $datesRead) { error }
+}`
+
+	var result struct {
+		Upsert hardcoverMutationResult `json:"upsert_user_book_reads"`
+	}
+
+	if err := c.query("UpsertDatesRead", query, map[string]any{"userBookId": userBookID, "datesRead": datesRead}, &result); err != nil {
+		return err
+	}
+
+	return result.Upsert.err()
+}
+
+// hardcoverMutationResult is the id and error returned by the mutations
+type hardcoverMutationResult struct {
+	ID    int     `json:"id"`
+	Error *string `json:"error"`
+}
+
+func (r hardcoverMutationResult) err() error {
+	if r.Error != nil && *r.Error != "" {
+		return fmt.Errorf("%w: %s", ErrHardcover, *r.Error)
+	}
+
+	return nil
+}
+
+// query posts the GraphQL operation and decodes the data into result
+func (c *HardcoverClient) query(operationName, query string, variables map[string]any, result any) error {
+	body, err := json.Marshal(map[string]any{
+		"operationName": operationName,
+		"query":         query,
+		"variables":     variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// The token on the account page may already have the Bearer prefix
+	token := c.Token
+	if !strings.HasPrefix(token, "Bearer ") {
+		token = "Bearer " + token
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s %s: %s", ErrHardcover, operationName, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return err
+	}
+
+	if len(response.Errors) > 0 {
+		messages := make([]string, 0, len(response.Errors))
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+
+		return fmt.Errorf("%w: %s: %s", ErrHardcover, operationName, strings.Join(messages, "; "))
+	}
+
+	return json.Unmarshal(response.Data, result)
+}
@@ -30,6 +30,36 @@ func main() {
 	case "goals":
 		os.Exit(cmd.Goals(os.Stdout))
 
+	case "history":
+		os.Exit(cmd.History(os.Stdout))
+
+	case "undo":
+		os.Exit(cmd.Undo(os.Stdout))
+
+	case "merge":
+		os.Exit(cmd.Merge(os.Stdout))
+
+	case "log":
+		os.Exit(cmd.Log(os.Stdout))
+
+	case "edit":
+		os.Exit(cmd.Edit(os.Stdout))
+
+	case "import":
+		os.Exit(cmd.Import(os.Stdout))
+
+	case "export":
+		os.Exit(cmd.Export(os.Stdout))
+
+	case "hardcover":
+		os.Exit(cmd.Hardcover(os.Stdout))
+
+	case "site":
+		os.Exit(cmd.Site(os.Stdout))
+
+	case "serve":
+		os.Exit(cmd.Serve(os.Stdout))
+
 	// case "gui":
 	//	os.Exit(cmd.Gui(os.Stdout))
 
@@ -39,6 +69,6 @@ func main() {
 }
 
 func usageRoot() {
-	fmt.Printf("usage: %s commands(gui, sync or stats) options\n", cliName)
+	fmt.Printf("usage: %s commands(sync, stats, goals, log, edit, import, export, hardcover, site, serve, history, undo or merge) options\n", cliName)
 	os.Exit(1)
 }
@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BookDetail is everything about one book: the stats with the shelves it is on, the progress milestones, each session
+// with the time since the previous one and the reading time of every day from the first to the last read
+type BookDetail struct {
+	StatsBook
+
+	Shelves    []string        `json:"shelves"`
+	Milestones []BookMilestone `json:"milestones"`
+
+	FirstRead string        `json:"first_read,omitempty"`
+	LastRead  string        `json:"last_read,omitempty"`
+	Sessions  []BookSession `json:"sessions"`
+	Days      []BookDay     `json:"days"`
+}
+
+// BookSession is a reading session with Gap, the seconds since the end of the previous session (0 for the first)
+type BookSession struct {
+	StatsRead
+
+	Gap int `json:"gap"`
+}
+
+// BookDay is the reading time of a day e.g. 2024-01-02
+type BookDay struct {
+	Date    string `json:"date"`
+	Seconds int    `json:"seconds"`
+}
+
+// BookMilestone is a progress milestone (25%, 50% or 75%) or the finish of a read-through
+type BookMilestone struct {
+	Name   string       `json:"name"`
+	Time   string       `json:"time"`
+	Device string       `json:"device,omitempty"`
+	Source FinishSource `json:"source,omitempty"`
+}
+
+// NewBookDetail returns the detail of the book with the canonical content ID, false if there are no stats for it
+func NewBookDetail(storage Storage, stats Stats, cid string) (BookDetail, bool) {
+	identity := NewContentIdentity(storage.Contents())
+
+	book, exists := stats.Content[identity.Canonical(cid)]
+	if !exists {
+		return BookDetail{}, false
+	}
+
+	return newBookDetail(storage, identity, shelvesByContent(storage, identity), book), true
+}
+
+// newBookDetail adds the shelves and milestones to the book. The progress milestones are from the events of every
+// alias and the finishes are from the read-throughs
+func newBookDetail(storage Storage, identity ContentIdentity, shelves map[string][]string, book StatsBook) BookDetail {
+	result := BookDetail{
+		StatsBook:  book,
+		Shelves:    make([]string, 0),
+		Milestones: make([]BookMilestone, 0),
+	}
+
+	result.Shelves = append(result.Shelves, shelves[book.BookID]...)
+
+	// Copies so sorting does not change the stats
+	result.Reads = append(make([]StatsRead, 0, len(book.Reads)), book.Reads...)
+	result.Bookmarks = append(make([]StatsBookmark, 0, len(book.Bookmarks)), book.Bookmarks...)
+
+	seen := map[string]bool{}
+
+	for _, alias := range identity.Aliases(book.BookID) {
+		for _, event := range storage.Events(alias) {
+			switch event.EventName {
+			case Progress25Event.String(), Progress50Event.String(), Progress75Event.String():
+			default:
+				continue
+			}
+
+			key := event.EventName + "|" + event.Time
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+
+			result.Milestones = append(result.Milestones, BookMilestone{Name: event.EventName, Time: event.Time, Device: event.Device})
+		}
+	}
+
+	for _, readThrough := range book.ReadThroughs {
+		if readThrough.IsFinished && readThrough.FinishedTime != "" {
+			result.Milestones = append(result.Milestones, BookMilestone{
+				Name:   FinishEvent.String(),
+				Time:   readThrough.FinishedTime,
+				Source: readThrough.FinishedSource,
+			})
+		}
+	}
+
+	sort.SliceStable(result.Milestones, func(i, j int) bool {
+		return result.Milestones[i].Time < result.Milestones[j].Time
+	})
+
+	sort.Slice(result.Reads, func(i, j int) bool {
+		return result.Reads[i].Time < result.Reads[j].Time
+	})
+
+	sort.Slice(result.Bookmarks, func(i, j int) bool {
+		return result.Bookmarks[i].Created < result.Bookmarks[j].Created
+	})
+
+	result.Sessions = make([]BookSession, 0, len(result.Reads))
+	result.Days = make([]BookDay, 0)
+
+	if len(result.Reads) == 0 {
+		return result
+	}
+
+	result.FirstRead = result.Reads[0].Time
+	result.LastRead = result.Reads[len(result.Reads)-1].Time
+
+	var previousEnd time.Time
+
+	daySeconds := map[string]int{}
+
+	for idx, read := range result.Reads {
+		start, end := read.Span()
+
+		session := BookSession{StatsRead: read}
+		if idx > 0 && start.After(previousEnd) {
+			session.Gap = int(start.Sub(previousEnd).Seconds())
+		}
+
+		if end.After(previousEnd) {
+			previousEnd = end
+		}
+
+		result.Sessions = append(result.Sessions, session)
+		daySeconds[start.Format(reportDateFmt)] += read.Duration
+	}
+
+	first, _ := time.Parse(StorageTimeFmt, result.FirstRead)
+	last, _ := time.Parse(StorageTimeFmt, result.LastRead)
+
+	for day := first.Truncate(24 * time.Hour); !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format(reportDateFmt)
+		result.Days = append(result.Days, BookDay{Date: date, Seconds: daySeconds[date]})
+	}
+
+	return result
+}
+
+// FindBook returns the detail of the book with the content ID, or the title (case insensitive) or else the only book
+// with the query in the title. Aliases of the same book are one match.
+func FindBook(storage Storage, stats Stats, query string) (BookDetail, error) {
+	contents := storage.Contents()
+	identity := NewContentIdentity(contents)
+	query = strings.TrimSpace(query)
+
+	if book, exists := stats.Content[identity.Canonical(query)]; exists {
+		return newBookDetail(storage, identity, shelvesByContent(storage, identity), book), nil
+	}
+
+	find := func(match func(title string) bool) []string {
+		result := make([]string, 0)
+
+		for cid, book := range stats.Content {
+			if match(strings.ToLower(book.Title)) {
+				result = append(result, cid)
+			}
+		}
+
+		sort.Strings(result)
+
+		return result
+	}
+
+	lowerQuery := strings.ToLower(query)
+
+	matches := find(func(title string) bool { return title == lowerQuery })
+	if len(matches) == 0 {
+		matches = find(func(title string) bool { return strings.Contains(title, lowerQuery) })
+	}
+
+	switch len(matches) {
+	case 0:
+		return BookDetail{}, fmt.Errorf("%w: %s", ErrContentNotFound, query)
+	case 1:
+		return newBookDetail(storage, identity, shelvesByContent(storage, identity), stats.Content[matches[0]]), nil
+	}
+
+	titles := make([]string, len(matches))
+	for idx := range matches {
+		titles[idx] = fmt.Sprintf("%s (%s)", stats.Content[matches[idx]].Title, matches[idx])
+	}
+
+	return BookDetail{}, fmt.Errorf("%w: %s (%s)", ErrContentAmbiguous, query, strings.Join(titles, ", "))
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindBook(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testDeviceBID = "test-device-b"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "file:///mnt/onboard/other/altered-carbon.epub"
+		testBookCID   = "/mnt/onboard/books/broken-angels.epub"
+	)
+
+	dir := t.TempDir()
+
+	storage, err := OpenStorageOrCreate(filepath.Join(dir, "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "0345457684", 550, true, true, 100)
+	storage.AddContent(testBookBID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, false, 10)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookBID, testDeviceBID, ReadEvent.String(), time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC), 300)
+	storage.AddEvent(testBookBID, testDeviceBID, ReadEvent.String(), time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), 300)
+	storage.AddEvent(testBookAID, testDeviceAID, Progress50Event.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 4, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddBookmark("b1", testBookBID, testBookBID, bookmarkTypeNote, "", 0, 0, 0, "Sleeve", "Body",
+		time.Date(2024, 1, 3, 8, 5, 0, 0, time.UTC), time.Date(2024, 1, 3, 8, 5, 0, 0, time.UTC))
+	storage.AddShelf("shelf-id", "Science Fiction", "Science Fiction", "UserTag", false)
+	storage.AddShelfContent("Science Fiction", testBookBID, false)
+
+	storage.AddContent(testBookCID, "Broken Angels", "Richard K. Morgan", "", "", 0, true, false, 0)
+
+	stats := NewStats(storage)
+
+	for _, query := range []string{testBookBID, "altered carbon", "carbon"} {
+		t.Run(query, func(t *testing.T) {
+			book, err := FindBook(storage, stats, query)
+			assert.NoError(t, err)
+
+			assert.Equal(t, testBookAID, book.BookID)
+			assert.Equal(t, []string{"Science Fiction"}, book.Shelves)
+			assert.Equal(t, "2024-01-01T20:00:00.000", book.FirstRead)
+			assert.Equal(t, "2024-01-03T09:00:00.000", book.LastRead)
+
+			assert.Equal(t, []BookMilestone{
+				{Name: "50%", Time: "2024-01-02T20:00:00.000", Device: testDeviceAID},
+				{Name: "Finish", Time: "2024-01-04T20:00:00.000", Source: FinishSourceEvent},
+			}, book.Milestones)
+
+			assert.Len(t, book.Sessions, 3)
+			assert.Equal(t, 0, book.Sessions[0].Gap)
+			assert.Equal(t, int((35*time.Hour + 50*time.Minute).Seconds()), book.Sessions[1].Gap)
+			assert.Equal(t, 55*60, book.Sessions[2].Gap)
+
+			assert.Equal(t, []BookDay{
+				{Date: "2024-01-01", Seconds: 600},
+				{Date: "2024-01-02", Seconds: 0},
+				{Date: "2024-01-03", Seconds: 600},
+			}, book.Days)
+
+			assert.Len(t, book.Bookmarks, 1)
+		})
+	}
+
+	t.Run("ambiguous", func(t *testing.T) {
+		_, err := FindBook(storage, stats, "r")
+		assert.ErrorIs(t, err, ErrContentAmbiguous)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := FindBook(storage, stats, "Matilda")
+		assert.ErrorIs(t, err, ErrContentNotFound)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		book, err := FindBook(storage, stats, "Altered Carbon")
+		assert.NoError(t, err)
+
+		fn := filepath.Join(dir, "book.html")
+		assert.NoError(t, NewBookChart(book, fn))
+
+		page, err := os.ReadFile(fn)
+		assert.NoError(t, err)
+		assert.Contains(t, string(page), "<title>01-01 Minutes Reading: 10</title>")
+		assert.Contains(t, string(page), "<p>Sleeve</p>")
+		assert.NotContains(t, string(page), "<script")
+	})
+}
@@ -0,0 +1,177 @@
+package pkg
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Size of the svg charts, they scale to the width of the page with the viewBox
+const (
+	svgWidth  = 600
+	svgHeight = 300
+
+	svgMarginLeft   = 44
+	svgMarginRight  = 10
+	svgMarginTop    = 30
+	svgMarginBottom = 24
+
+	svgTicks = 5
+
+	// svgMaxLabels is the most x axis labels, a daily chart only labels every few days
+	svgMaxLabels = 12
+)
+
+// chartSeries is a named series of values per label, drawn as bars or as a line
+type chartSeries struct {
+	Label  string
+	Values []float64
+	Color  string
+	Line   bool
+}
+
+// svgChart renders the series as an inline svg chart with a legend, grid and a title per value for the tooltip.
+// Bar series are grouped side by side per label and line series are drawn over them
+func svgChart(title string, labels []string, series []chartSeries) template.HTML {
+	plotWidth := float64(svgWidth - svgMarginLeft - svgMarginRight)
+	plotHeight := float64(svgHeight - svgMarginTop - svgMarginBottom)
+
+	maxValue := 0.0
+	bars := 0
+
+	for _, s := range series {
+		for _, value := range s.Values {
+			maxValue = math.Max(maxValue, value)
+		}
+
+		if !s.Line {
+			bars++
+		}
+	}
+
+	maxValue = niceCeil(maxValue)
+
+	y := func(value float64) float64 {
+		return float64(svgMarginTop) + plotHeight - value/maxValue*plotHeight
+	}
+
+	groupWidth := plotWidth / float64(max(len(labels), 1))
+	x := func(idx int) float64 {
+		return float64(svgMarginLeft) + groupWidth*float64(idx) + groupWidth/2
+	}
+
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, `<svg class="chart" viewBox="0 0 %d %d" role="img" aria-label="%s" xmlns="http://www.w3.org/2000/svg">`,
+		svgWidth, svgHeight, template.HTMLEscapeString(title))
+
+	// Grid and y axis labels
+	for tick := 0; tick <= svgTicks; tick++ {
+		value := maxValue / svgTicks * float64(tick)
+
+		fmt.Fprintf(b, `<line class="grid" x1="%d" y1="%s" x2="%d" y2="%s"/>`,
+			svgMarginLeft, svgNum(y(value)), svgWidth-svgMarginRight, svgNum(y(value)))
+		fmt.Fprintf(b, `<text x="%d" y="%s" text-anchor="end" dominant-baseline="middle">%s</text>`,
+			svgMarginLeft-6, svgNum(y(value)), svgNum(value))
+	}
+
+	fmt.Fprintf(b, `<line class="axis" x1="%d" y1="%s" x2="%d" y2="%s"/>`,
+		svgMarginLeft, svgNum(y(0)), svgWidth-svgMarginRight, svgNum(y(0)))
+
+	// x axis labels
+	labelStep := (len(labels) + svgMaxLabels - 1) / svgMaxLabels
+
+	for idx, label := range labels {
+		if idx%max(labelStep, 1) != 0 {
+			continue
+		}
+
+		fmt.Fprintf(b, `<text x="%s" y="%d" text-anchor="middle">%s</text>`,
+			svgNum(x(idx)), svgHeight-8, template.HTMLEscapeString(label))
+	}
+
+	// Bars then lines
+	barWidth := groupWidth * 0.7 / float64(max(bars, 1))
+	bar := 0
+
+	for _, s := range series {
+		if s.Line {
+			continue
+		}
+
+		for idx, value := range s.Values {
+			left := x(idx) - groupWidth*0.35 + barWidth*float64(bar)
+
+			fmt.Fprintf(b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s" fill-opacity="0.6" stroke="%s"><title>%s</title></rect>`,
+				svgNum(left), svgNum(y(value)), svgNum(barWidth), svgNum(y(0)-y(value)), s.Color, s.Color,
+				svgTitle(s.Label, labels, idx, value))
+		}
+
+		bar++
+	}
+
+	for _, s := range series {
+		if !s.Line {
+			continue
+		}
+
+		points := make([]string, len(s.Values))
+		for idx, value := range s.Values {
+			points[idx] = svgNum(x(idx)) + "," + svgNum(y(value))
+		}
+
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), s.Color)
+
+		for idx, value := range s.Values {
+			fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="3" fill="%s"><title>%s</title></circle>`,
+				svgNum(x(idx)), svgNum(y(value)), s.Color, svgTitle(s.Label, labels, idx, value))
+		}
+	}
+
+	// Legend
+	legendX := float64(svgMarginLeft)
+	for _, s := range series {
+		fmt.Fprintf(b, `<rect x="%s" y="8" width="12" height="12" fill="%s" fill-opacity="0.6" stroke="%s"/>`,
+			svgNum(legendX), s.Color, s.Color)
+		fmt.Fprintf(b, `<text x="%s" y="18">%s</text>`, svgNum(legendX+16), template.HTMLEscapeString(s.Label))
+
+		legendX += 16 + float64(len(s.Label))*6.5 + 16
+	}
+
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String()) //nolint:gosec // every text in the svg is escaped
+}
+
+// niceCeil rounds up to 1, 2 or 5 times a power of ten so the grid has round numbers, at least 1
+func niceCeil(value float64) float64 {
+	if value <= 1 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+
+	for _, step := range []float64{1, 2, 5, 10} {
+		if step*magnitude >= value {
+			return step * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+// svgNum formats the number with at most 2 decimals e.g. 12.5
+func svgNum(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+func svgTitle(series string, labels []string, idx int, value float64) string {
+	label := ""
+	if idx < len(labels) {
+		label = labels[idx]
+	}
+
+	return template.HTMLEscapeString(fmt.Sprintf("%s %s: %s", label, series, svgNum(value)))
+}
@@ -3,16 +3,16 @@ package pkg
 import (
 	"embed"
 	_ "embed"
-	"encoding/json"
 	"fmt"
+	"html/template"
 	"os"
-	"text/template"
 	"time"
 )
 
 const (
 	fsPrefix       = "files/"
 	pageTemplateFn = "template.html"
+	bookTemplateFn = "template-book.html"
 )
 
 var (
@@ -30,16 +30,16 @@ type chartTemplateData struct {
 	BooksFinished   []chartTemplateStat
 	BooksRead       int
 	BookReadingTime string
-	BookReadCount   string
 
 	ArticlesFinished   []chartTemplateStat
 	ArticlesRead       int
 	ArticleReadingTime string
-	ArticleReadCount   string
 
-	TotalReadingTime string
+	// FinishedChart and ReadingTimeChart are inline svg so the page renders without the network
+	FinishedChart    template.HTML
+	ReadingTimeChart template.HTML
 
-	ReadstatCSS string
+	ReadstatCSS template.CSS
 }
 
 type chartTemplateStat struct {
@@ -49,6 +49,12 @@ type chartTemplateStat struct {
 	Duration string
 	Sessions int
 	Month    string
+
+	// ReadThrough is the number of the read when the book was re-read
+	ReadThrough int
+
+	// Estimated is true when the finish month is estimated
+	Estimated bool
 }
 
 const (
@@ -62,7 +68,7 @@ func NewChart(stats Stats, year int, filename string) error {
 	months := []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
 
 	data := chartTemplateData{
-		ReadstatCSS: readstatCSS,
+		ReadstatCSS: template.CSS(readstatCSS), //nolint:gosec // embedded css
 
 		Title: fmt.Sprintf(titleFmt, year),
 		// Description: "reading stats todo description",
@@ -71,16 +77,14 @@ func NewChart(stats Stats, year int, filename string) error {
 		ArticlesRead: len(stats.ArticlesFinishedYear(year)),
 	}
 
-	bookReadCount := []int{}
-
-	articleReadCount := []int{}
-
-	totalReadTime := make([]float32, 12)
+	bookReadCount := make([]float64, 0, 12)
+	articleReadCount := make([]float64, 0, 12)
+	totalReadTime := make([]float64, 12)
 
 	for idx := 1; idx <= 12; idx++ {
 		finBooks := stats.BooksFinishedMonth(year, idx)
 		for jdx := range finBooks {
-			totalReadTime[idx-1] += float32(finBooks[jdx].ReadSeconds())
+			totalReadTime[idx-1] += float64(finBooks[jdx].ReadSeconds()) / 3600
 
 			data.BooksFinished = append(data.BooksFinished, chartTemplateStat{
 				Title:    finBooks[jdx].Title,
@@ -88,12 +92,15 @@ func NewChart(stats Stats, year int, filename string) error {
 				Duration: HumanizeDurationShort(time.Second * time.Duration(finBooks[jdx].ReadSeconds())),
 				Sessions: finBooks[jdx].NumSessions(),
 				Month:    months[idx],
+
+				ReadThrough: finBooks[jdx].ReadThrough,
+				Estimated:   finBooks[jdx].FinishedSource.IsEstimated(),
 			})
 		}
 
 		finArts := stats.ArticlesFinishedMonth(year, idx)
 		for jdx := range finArts {
-			totalReadTime[idx-1] += float32(finArts[jdx].ReadSeconds())
+			totalReadTime[idx-1] += float64(finArts[jdx].ReadSeconds()) / 3600
 
 			data.ArticlesFinished = append(data.ArticlesFinished, chartTemplateStat{
 				Title:    finArts[jdx].Title,
@@ -104,25 +111,29 @@ func NewChart(stats Stats, year int, filename string) error {
 			})
 		}
 
-		bookReadCount = append(bookReadCount, len(stats.BooksFinishedMonth(year, idx)))
-
-		articleReadCount = append(articleReadCount, len(stats.ArticlesFinishedMonth(year, idx)))
+		bookReadCount = append(bookReadCount, float64(len(stats.BooksFinishedMonth(year, idx))))
+		articleReadCount = append(articleReadCount, float64(len(stats.ArticlesFinishedMonth(year, idx))))
 	}
 
 	data.BookReadingTime = HumanizeDurationShort(time.Second * time.Duration(stats.BooksSecondsReadYear(year)))
 	data.ArticleReadingTime = HumanizeDurationShort(time.Second * time.Duration(stats.ArticlesSecondsReadYear(year)))
 
-	bC, _ := json.Marshal(bookReadCount)
-	data.BookReadCount = string(bC)
+	monthLabels := make([]string, 0, 12)
+	for idx := 1; idx <= 12; idx++ {
+		monthLabels = append(monthLabels, months[idx][:3])
+	}
 
-	aC, _ := json.Marshal(articleReadCount)
-	data.ArticleReadCount = string(aC)
+	data.FinishedChart = svgChart("Books & Articles", monthLabels, []chartSeries{
+		{Label: "Books Read", Values: bookReadCount, Color: "rgb(255, 99, 132)"},
+		{Label: "Articles Read", Values: articleReadCount, Color: "rgb(54, 162, 235)", Line: true},
+	})
 
-	tC, _ := json.Marshal(totalReadTime)
-	data.TotalReadingTime = string(tC)
+	data.ReadingTimeChart = svgChart("Hours Reading", monthLabels, []chartSeries{
+		{Label: "Hours Reading", Values: totalReadTime, Color: "rgb(75, 192, 192)", Line: true},
+	})
 
 	// Write template!
-	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o644)
+	fp, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
@@ -143,3 +154,45 @@ func NewChart(stats Stats, year int, filename string) error {
 
 	return nil
 }
+
+type bookChartTemplateData struct {
+	Book       BookDetail
+	DailyChart template.HTML
+
+	ReadstatCSS template.CSS
+}
+
+// NewBookChart writes the detail of one book as a single page html to filename
+func NewBookChart(book BookDetail, filename string) error {
+	labels := make([]string, 0, len(book.Days))
+	minutes := make([]float64, 0, len(book.Days))
+
+	for _, day := range book.Days {
+		labels = append(labels, day.Date[len("2006-"):])
+		minutes = append(minutes, float64(day.Seconds)/60)
+	}
+
+	data := bookChartTemplateData{
+		Book: book,
+		DailyChart: svgChart("Minutes Reading per Day", labels, []chartSeries{
+			{Label: "Minutes Reading", Values: minutes, Color: "rgb(75, 192, 192)"},
+		}),
+		ReadstatCSS: template.CSS(readstatCSS), //nolint:gosec // embedded css
+	}
+
+	fp, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	tmpl, err := template.New(bookTemplateFn).Funcs(templateFuncs).ParseFS(templateFS, fsPrefix+bookTemplateFn)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(fp, data)
+}
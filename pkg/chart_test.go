@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChartSelfContained(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+	)
+
+	dir := t.TempDir()
+
+	storage, err := OpenStorageOrCreate(filepath.Join(dir, "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered <Carbon>", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 5400)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+
+	fn := filepath.Join(dir, "report.html")
+	assert.NoError(t, os.WriteFile(fn, make([]byte, 100000), 0o644))
+
+	assert.NoError(t, NewChart(NewStats(storage), 2024, fn))
+
+	pageBytes, err := os.ReadFile(fn)
+	assert.NoError(t, err)
+
+	page := string(pageBytes)
+
+	assert.NotContains(t, page, "<script")
+	assert.NotContains(t, page, "<link")
+	assert.NotContains(t, page, "\x00")
+	assert.Contains(t, page, "Altered &lt;Carbon&gt;")
+	assert.Contains(t, page, `<svg class="chart"`)
+	assert.Contains(t, page, "<title>Jan Books Read: 1</title>")
+	assert.Contains(t, page, "<title>Jan Hours Reading: 1.5</title>")
+	assert.Contains(t, page, "svg.chart text {")
+}
+
+func TestNiceCeil(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected float64
+	}{
+		{0, 1},
+		{0.4, 1},
+		{1.5, 2},
+		{3, 5},
+		{7, 10},
+		{12, 20},
+		{250, 500},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, niceCeil(tt.value))
+	}
+}
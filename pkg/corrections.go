@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrContentNotFound  = errors.New("content not found")
+	ErrContentAmbiguous = errors.New("more than one content matches")
+)
+
+// FindContent returns the content with the ID, or the only content with the title (case insensitive)
+func FindContent(contents []StorageContent, query string) (StorageContent, error) {
+	matches := make([]StorageContent, 0)
+
+	for _, content := range contents {
+		if content.ID == query {
+			return content, nil
+		}
+
+		if strings.EqualFold(content.Title, strings.TrimSpace(query)) {
+			matches = append(matches, content)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return StorageContent{}, fmt.Errorf("%w: %s", ErrContentNotFound, query)
+	case 1:
+		return matches[0], nil
+	}
+
+	ids := make([]string, len(matches))
+	for idx := range matches {
+		ids[idx] = matches[idx].ID
+	}
+
+	return StorageContent{}, fmt.Errorf("%w: %s (%s)", ErrContentAmbiguous, query, strings.Join(ids, ", "))
+}
+
+// isDeletedSession is true when the Read event at the time was deleted by the correction
+func (c StorageCorrection) isDeletedSession(t string) bool {
+	for _, deleted := range c.DeletedSessions {
+		if deleted == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canonicalCorrections combines the corrections of all aliases of a book into one per canonical content ID. A
+// correction of the canonical content wins over an alias
+func canonicalCorrections(identity ContentIdentity, corrections map[string]StorageCorrection) map[string]StorageCorrection {
+	result := make(map[string]StorageCorrection, len(corrections))
+
+	apply := func(cid string, correction StorageCorrection) {
+		merged := result[cid]
+
+		if correction.Title != "" {
+			merged.Title = correction.Title
+		}
+
+		if correction.Author != "" {
+			merged.Author = correction.Author
+		}
+
+		if correction.Finished != nil {
+			merged.Finished = correction.Finished
+			merged.FinishedTime = correction.FinishedTime
+		}
+
+		merged.DeletedSessions = append(merged.DeletedSessions, correction.DeletedSessions...)
+		merged.Hidden = merged.Hidden || correction.Hidden
+
+		result[cid] = merged
+	}
+
+	cIDs := make([]string, 0, len(corrections))
+	for cID := range corrections {
+		cIDs = append(cIDs, cID)
+	}
+
+	sort.Strings(cIDs)
+
+	for _, cID := range cIDs {
+		if cid := identity.Canonical(cID); cid != cID {
+			apply(cid, corrections[cID])
+		}
+	}
+
+	for _, cID := range cIDs {
+		if identity.Canonical(cID) == cID {
+			apply(cID, corrections[cID])
+		}
+	}
+
+	return result
+}
+
+// MatchSessions returns the times of the Read events of the content and its aliases that start in the same minute as
+// t. Times are compared as written in storage e.g. "2024-01-02 20:15" matches "2024-01-02T20:15:42.000"
+func MatchSessions(storage Storage, cID string, t time.Time) []string {
+	identity := NewContentIdentity(storage.Contents())
+
+	minute := t.Format("2006-01-02T15:04")
+	result := make([]string, 0)
+
+	for _, alias := range identity.Aliases(cID) {
+		for _, event := range storage.Events(alias) {
+			if event.EventName == ReadEvent.String() && strings.HasPrefix(event.Time, minute) {
+				result = append(result, event.Time)
+			}
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStatsCorrections(t *testing.T) {
+	const (
+		testDeviceAID  = "test-device-a"
+		testBookAID    = "books/test-book-a.epub"
+		testBookBID    = "books/test-book-b.epub"
+		testArticleAID = "articles/test-article-a.epub"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. K. Morgan", "", "", 123, true, false, 50)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 3, 2, 20, 15, 42, 0, time.UTC), 10800)
+
+	storage.AddContent(testBookBID, "Matilda", "Roald Dahl", "", "", 123, true, true, 100)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 4, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookBID, testDeviceAID, FinishEvent.String(), time.Date(2024, 4, 1, 20, 10, 0, 0, time.UTC), 0)
+
+	storage.AddContent(testArticleAID, "Spam", "Spammer", "", "", 10, false, true, 100)
+	storage.AddEvent(testArticleAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 4, 1, 21, 0, 0, 0, time.UTC), 60)
+
+	deleted := MatchSessions(storage, testBookAID, time.Date(2024, 3, 2, 20, 15, 0, 0, time.Local))
+	assert.Equal(t, []string{"2024-03-02T20:15:42.000"}, deleted)
+
+	finished, unfinished := true, false
+	storage.SetCorrection(testBookAID, StorageCorrection{
+		Author:          "Richard K. Morgan",
+		Finished:        &finished,
+		FinishedTime:    "2024-03-05T00:00:00.000",
+		DeletedSessions: deleted,
+	})
+	storage.SetCorrection(testBookBID, StorageCorrection{Finished: &unfinished})
+	storage.SetCorrection(testArticleAID, StorageCorrection{Hidden: true})
+
+	// Sync does not change the corrections
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. K. Morgan", "", "", 123, true, false, 60)
+
+	stats := NewStats(storage)
+
+	bookA := stats.Content[testBookAID]
+	assert.Equal(t, "Richard K. Morgan", bookA.Author)
+	assert.Len(t, bookA.Reads, 1)
+	assert.Equal(t, "2024-03-05T00:00:00.000", bookA.FinishedTime)
+	assert.Equal(t, FinishSourceCorrection, bookA.FinishedSource)
+
+	assert.False(t, stats.Content[testBookBID].IsFinished)
+
+	_, exists := stats.Content[testArticleAID]
+	assert.False(t, exists)
+
+	finished2024 := stats.BooksFinishedYear(2024)
+	assert.Len(t, finished2024, 1)
+	assert.Equal(t, testBookAID, finished2024[0].BookID)
+	assert.Equal(t, 600, stats.BooksSecondsReadMonth(2024, 3))
+
+	// A zero correction removes it
+	storage.SetCorrection(testArticleAID, StorageCorrection{})
+	assert.Len(t, storage.Corrections(), 2)
+}
+
+func TestNewStatsCorrectedFinish(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "books/test-book-a.epub"
+	)
+
+	finished := true
+
+	t.Run("replaces a wrong finish before later sessions", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 123, true, true, 100)
+		storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+		storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC), 600)
+		storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 9, 20, 0, 0, 0, time.UTC), 600)
+
+		storage.SetCorrection(testBookAID, StorageCorrection{Finished: &finished, FinishedTime: "2024-01-10T00:00:00.000"})
+
+		stats := NewStats(storage)
+
+		book := stats.Content[testBookAID]
+		assert.Len(t, book.ReadThroughs, 1)
+		assert.Len(t, book.ReadThroughs[0].Reads, 2)
+		assert.Equal(t, "2024-01-10T00:00:00.000", book.FinishedTime)
+		assert.Equal(t, FinishSourceCorrection, book.FinishedSource)
+		assert.Equal(t, 1, book.ReadCount)
+		assert.Len(t, stats.BooksFinishedYear(2024), 1)
+	})
+
+	t.Run("replaces only the finish of the corrected read-through", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 123, true, true, 100)
+		storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 600)
+		storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 0)
+		storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 2, 1, 20, 0, 0, 0, time.UTC), 600)
+		storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 2, 3, 20, 0, 0, 0, time.UTC), 0)
+
+		storage.SetCorrection(testBookAID, StorageCorrection{Finished: &finished, FinishedTime: "2024-02-10T00:00:00.000"})
+
+		book := NewStats(storage).Content[testBookAID]
+		assert.Len(t, book.ReadThroughs, 2)
+		assert.Equal(t, "2024-01-02T20:00:00.000", book.ReadThroughs[0].FinishedTime)
+		assert.Equal(t, FinishSourceEvent, book.ReadThroughs[0].FinishedSource)
+		assert.Equal(t, "2024-02-10T00:00:00.000", book.ReadThroughs[1].FinishedTime)
+		assert.Equal(t, FinishSourceCorrection, book.ReadThroughs[1].FinishedSource)
+		assert.Equal(t, 2, book.ReadCount)
+	})
+}
+
+func TestFindContent(t *testing.T) {
+	contents := []StorageContent{
+		{ID: "books/a.epub", Title: "Carrie"},
+		{ID: "books/b.epub", Title: "It"},
+		{ID: "books/c.epub", Title: "it"},
+	}
+
+	content, err := FindContent(contents, "books/b.epub")
+	assert.NoError(t, err)
+	assert.Equal(t, "It", content.Title)
+
+	content, err = FindContent(contents, "carrie")
+	assert.NoError(t, err)
+	assert.Equal(t, "books/a.epub", content.ID)
+
+	_, err = FindContent(contents, "IT")
+	assert.ErrorIs(t, err, ErrContentAmbiguous)
+
+	_, err = FindContent(contents, "Misery")
+	assert.ErrorIs(t, err, ErrContentNotFound)
+}
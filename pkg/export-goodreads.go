@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const goodreadsDateFmt = "2006/01/02"
+
+// goodreadsExclusiveShelves are the Goodreads read status shelves, a book is on exactly one
+var goodreadsExclusiveShelves = []string{goodreadsReadShelf, "currently-reading", "to-read"}
+
+// ExportGoodreadsCSV writes every book as a Goodreads import csv. The Date Read is the last finish, the exclusive
+// shelf is from the finished state and sessions and other shelves are Bookshelves. Articles are not exported.
+func ExportGoodreadsCSV(storage Storage, w io.Writer, options ExportOptions) error {
+	stats := options.stats(storage)
+	shelves := shelvesByContent(storage, NewContentIdentity(storage.Contents()))
+
+	books := make([]StatsBook, 0, len(stats.Content))
+	for _, book := range stats.Content {
+		if book.IsBook {
+			books = append(books, book)
+		}
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Title != books[j].Title {
+			return books[i].Title < books[j].Title
+		}
+
+		return books[i].BookID < books[j].BookID
+	})
+
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Title", "Author", "ISBN", "ISBN13", "My Rating", "Number of Pages", "Date Read",
+		"Date Added", "Bookshelves", "Exclusive Shelf", "Read Count"})
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		exclusiveShelf := "to-read"
+		switch {
+		case book.IsFinished:
+			exclusiveShelf = goodreadsReadShelf
+		case len(book.Reads) > 0:
+			exclusiveShelf = "currently-reading"
+		}
+
+		bookshelves := make([]string, 0)
+		for _, shelfName := range shelves[book.BookID] {
+			shelf := slugify(shelfName)
+			if shelf != "" && !slices.Contains(goodreadsExclusiveShelves, shelf) && !slices.Contains(bookshelves, shelf) {
+				bookshelves = append(bookshelves, shelf)
+			}
+		}
+
+		pages := ""
+		if book.Words > 0 {
+			pages = strconv.Itoa((book.Words + wordsPerPage - 1) / wordsPerPage)
+		}
+
+		rating := ""
+		if book.Rating > 0 {
+			rating = strconv.Itoa(book.Rating)
+		}
+
+		readCount := ""
+		if book.ReadCount > 0 {
+			readCount = strconv.Itoa(book.ReadCount)
+		}
+
+		isbn, isbn13 := book.ISBN, normaliseISBN(book.ISBN)
+		if isbn == isbn13 {
+			isbn = ""
+		}
+
+		err := writer.Write([]string{
+			book.Title,
+			book.Author,
+			isbn,
+			isbn13,
+			rating,
+			pages,
+			goodreadsDate(book.FinishedTime),
+			goodreadsDate(book.FirstReadTime()),
+			strings.Join(bookshelves, ", "),
+			exclusiveShelf,
+			readCount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// goodreadsDate formats the storage time as a Goodreads date e.g. 2024/01/02, empty if not set
+func goodreadsDate(ts string) string {
+	t, err := time.Parse(StorageTimeFmt, ts)
+	if err != nil {
+		return ""
+	}
+
+	return t.Format(goodreadsDateFmt)
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportGoodreadsCSV(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "file:///mnt/onboard/other/altered-carbon.epub"
+		testBookCID   = "/mnt/onboard/books/matilda.epub"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "0345457684", 550, true, true, 100)
+	storage.AddContent(testBookBID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, false, 10)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookBID, testDeviceAID, FinishEvent.String(), time.Date(2024, 6, 2, 20, 0, 0, 0, time.UTC), 0)
+
+	storage.AddContent(testBookCID, "Matilda", "Roald Dahl", "", "", 0, true, false, 0)
+	storage.AddContent("articles/a", "Article", "", "test.com", "", 100, false, true, 100)
+
+	storage.AddShelf("shelf-id", "Science Fiction", "Science Fiction", "UserTag", false)
+	storage.AddShelfContent("Science Fiction", testBookBID, false)
+	storage.AddShelf("read", "read", "read", "UserTag", false)
+	storage.AddShelfContent("read", testBookAID, false)
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, ExportGoodreadsCSV(storage, &buf, ExportOptions{}))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Title", "Author", "ISBN", "ISBN13", "My Rating", "Number of Pages", "Date Read", "Date Added", "Bookshelves", "Exclusive Shelf", "Read Count"},
+		{"Altered Carbon", "Richard K. Morgan", "0345457684", "9780345457684", "", "2", "2024/06/02", "2024/01/01", "science-fiction", "read", "2"},
+		{"Matilda", "Roald Dahl", "", "", "", "", "", "", "", "to-read", ""},
+	}, rows)
+}
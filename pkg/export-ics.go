@@ -0,0 +1,233 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	icsDateFmt     = "20060102"
+	icsDateTimeFmt = "20060102T150405"
+	icsUIDDomain   = "kobo-readstat"
+
+	// icsLineLength is the maximum octets of a line, longer lines are folded
+	icsLineLength = 75
+)
+
+// ExportICSCalendar writes the reading sessions as events and each finish as an all-day event in the iCalendar format.
+// The times are floating (no time zone) like the device times. The UIDs are from the ISBN or normalised title and author
+// and the start of the session or read-through, so importing the calendar again updates the events instead of
+// duplicating them, even after an earlier read or another alias of the book is added
+func ExportICSCalendar(storage Storage, w io.Writer, options ExportOptions) error {
+	return writeICS(storage, options.stats(storage), w, options, time.Now())
+}
+
+// icsEvent is a VEVENT. Start and End are dates for an all-day event
+type icsEvent struct {
+	UID         string
+	Start       string
+	End         string
+	AllDay      bool
+	Summary     string
+	Description string
+	Categories  string
+}
+
+func writeICS(storage Storage, stats Stats, w io.Writer, options ExportOptions, now time.Time) error {
+	models := map[string]string{}
+	for _, device := range storage.Devices() {
+		models[device.Device] = device.Model
+	}
+
+	events := make([]icsEvent, 0)
+
+	for _, book := range stats.Content {
+		key := icsBookKey(book)
+
+		category := "Article"
+		if book.IsBook {
+			category = "Book"
+		}
+
+		for _, read := range book.Reads {
+			if !options.inRange(read.Time) {
+				continue
+			}
+
+			start, end := read.Span()
+
+			description := []string{}
+			if book.Author != "" {
+				description = append(description, "Author: "+book.Author)
+			}
+
+			if read.Device != "" {
+				device := read.Device
+				if model := models[read.Device]; model != "" {
+					device = model
+				}
+
+				description = append(description, "Device: "+device)
+			}
+
+			description = append(description, "Duration: "+HumanizeDurationShort(time.Duration(read.Duration)*time.Second))
+
+			events = append(events, icsEvent{
+				UID:         icsUID("session", key, read.Time, read.Device),
+				Start:       start.Format(icsDateTimeFmt),
+				End:         end.Format(icsDateTimeFmt),
+				Summary:     book.Title,
+				Description: strings.Join(description, "\n"),
+				Categories:  category,
+			})
+		}
+
+		for _, readThrough := range book.ReadThroughs {
+			if !readThrough.IsFinished || readThrough.FinishedTime == "" || !options.inRange(readThrough.FinishedTime) {
+				continue
+			}
+
+			finished, err := time.Parse(StorageTimeFmt, readThrough.FinishedTime)
+			if err != nil {
+				continue
+			}
+
+			description := []string{}
+			if book.Author != "" {
+				description = append(description, "Author: "+book.Author)
+			}
+
+			description = append(description, "Reading time: "+HumanizeDurationShort(time.Duration(readThrough.ReadSeconds())*time.Second))
+
+			if readThrough.FinishedSource.IsEstimated() {
+				description = append(description, "Finish time estimated")
+			}
+
+			events = append(events, icsEvent{
+				UID:         icsUID("finish", key, readThrough.Start),
+				Start:       finished.Format(icsDateFmt),
+				End:         finished.AddDate(0, 0, 1).Format(icsDateFmt),
+				AllDay:      true,
+				Summary:     "Finished " + book.Title,
+				Description: strings.Join(description, "\n"),
+				Categories:  category,
+			})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Start != events[j].Start {
+			return events[i].Start < events[j].Start
+		}
+
+		return events[i].UID < events[j].UID
+	})
+
+	ics := &icsWriter{w: w}
+
+	ics.line("BEGIN:VCALENDAR")
+	ics.line("VERSION:2.0")
+	ics.line("PRODID:-//timchurchard//kobo-readstat//EN")
+	ics.line("CALSCALE:GREGORIAN")
+	ics.line("X-WR-CALNAME:Reading")
+
+	stamp := now.UTC().Format(icsDateTimeFmt) + "Z"
+
+	for _, event := range events {
+		ics.line("BEGIN:VEVENT")
+		ics.line("UID:" + event.UID)
+		ics.line("DTSTAMP:" + stamp)
+
+		if event.AllDay {
+			ics.line("DTSTART;VALUE=DATE:" + event.Start)
+			ics.line("DTEND;VALUE=DATE:" + event.End)
+			ics.line("TRANSP:TRANSPARENT")
+		} else {
+			ics.line("DTSTART:" + event.Start)
+			ics.line("DTEND:" + event.End)
+		}
+
+		ics.line("SUMMARY:" + icsEscape(event.Summary))
+		ics.line("DESCRIPTION:" + icsEscape(event.Description))
+		ics.line("CATEGORIES:" + icsEscape(event.Categories))
+		ics.line("END:VEVENT")
+	}
+
+	ics.line("END:VCALENDAR")
+
+	return ics.err
+}
+
+// icsBookKey returns the normalised ISBN, title and author or content ID of the book. Unlike the canonical content ID it
+// does not depend on which aliases of the book are known
+func icsBookKey(book StatsBook) string {
+	if isbn := normaliseISBN(book.ISBN); isbn != "" {
+		return "isbn:" + isbn
+	}
+
+	title := normaliseTitle(book.Title)
+	author := normaliseAuthor(book.Author)
+
+	if title != "" && author != "" {
+		return "title:" + title + "|" + author
+	}
+
+	return book.BookID
+}
+
+// icsUID returns a stable UID of the event kind and the parts
+func icsUID(kind string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+
+	return fmt.Sprintf("%s-%x@%s", kind, sum[:12], icsUIDDomain)
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsEscape escapes a TEXT value
+func icsEscape(value string) string {
+	return icsEscaper.Replace(value)
+}
+
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+// line writes the content line with CRLF, folding it at icsLineLength octets without splitting a UTF-8 character
+func (i *icsWriter) line(content string) {
+	if i.err != nil {
+		return
+	}
+
+	var sb strings.Builder
+
+	limit := icsLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Start(content[cut]) {
+			cut--
+		}
+
+		sb.WriteString(content[:cut])
+		sb.WriteString("\r\n ")
+		content = content[cut:]
+
+		// The leading space of a continuation line counts
+		limit = icsLineLength - 1
+	}
+
+	sb.WriteString(content)
+	sb.WriteString("\r\n")
+
+	_, i.err = io.WriteString(i.w, sb.String())
+}
+
+// isUTF8Start is true when the byte is not a UTF-8 continuation byte
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportICS(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddDevice(testDeviceAID, "Libra 2")
+	storage.AddContent(testBookAID, "Altered Carbon, a novel", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), 60)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 3600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 0)
+
+	now := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	year := ExportOptions{From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	export := func(options ExportOptions) string {
+		var buf bytes.Buffer
+		assert.NoError(t, writeICS(storage, NewStats(storage), &buf, options, now))
+
+		return buf.String()
+	}
+
+	bookKey := "title:altered carbon a novel|morgan richard"
+	sessionUID := icsUID("session", bookKey, "2024-01-01T20:00:00.000", testDeviceAID)
+	finishUID := icsUID("finish", bookKey, "2023-12-31T20:00:00.000")
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//timchurchard//kobo-readstat//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Reading",
+		"BEGIN:VEVENT",
+		"UID:" + sessionUID,
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART:20240101T200000",
+		"DTEND:20240101T210000",
+		"SUMMARY:Altered Carbon\\, a novel",
+		"DESCRIPTION:Author: Richard K. Morgan\\nDevice: Libra 2\\nDuration: 1h 0m 0s",
+		"CATEGORIES:Book",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:" + finishUID,
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART;VALUE=DATE:20240102",
+		"DTEND;VALUE=DATE:20240103",
+		"TRANSP:TRANSPARENT",
+		"SUMMARY:Finished Altered Carbon\\, a novel",
+		"DESCRIPTION:Author: Richard K. Morgan\\nReading time: 1h 1m 0s",
+		"CATEGORIES:Book",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	assert.Equal(t, expected, export(year))
+
+	t.Run("stable uids", func(t *testing.T) {
+		all := export(ExportOptions{})
+		assert.Equal(t, 3, strings.Count(all, "BEGIN:VEVENT"))
+		assert.Contains(t, all, "UID:"+sessionUID+"\r\n")
+		assert.Contains(t, all, "UID:"+finishUID+"\r\n")
+	})
+
+	t.Run("importing an earlier read keeps the finish uid", func(t *testing.T) {
+		imported := NewCopyOnWriteStorage(storage)
+		imported.AddEvent(testBookAID, "", ReadEvent.String(), time.Date(2020, 5, 1, 20, 0, 0, 0, time.UTC), 600)
+		imported.AddEvent(testBookAID, "", FinishEvent.String(), time.Date(2020, 5, 2, 20, 0, 0, 0, time.UTC), 0)
+
+		var buf bytes.Buffer
+		assert.NoError(t, writeICS(imported, NewStats(imported), &buf, year, now))
+
+		assert.Contains(t, buf.String(), "UID:"+finishUID+"\r\nDTSTAMP:20240201T120000Z\r\nDTSTART;VALUE=DATE:20240102\r\n")
+	})
+
+	t.Run("adding an alias keeps the uids", func(t *testing.T) {
+		const testBookBID = "/mnt/onboard/a/altered-carbon.kepub.epub"
+
+		aliased := NewCopyOnWriteStorage(storage)
+		aliased.AddContent(testBookBID, "Altered Carbon, a novel", "Richard K. Morgan", "", "", 550, true, false, 10)
+		aliased.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 600)
+
+		stats := NewStats(aliased)
+		assert.Len(t, stats.Content, 1)
+
+		var buf bytes.Buffer
+		assert.NoError(t, writeICS(aliased, stats, &buf, year, now))
+
+		assert.Contains(t, buf.String(), "UID:"+sessionUID+"\r\n")
+		assert.Contains(t, buf.String(), "UID:"+finishUID+"\r\n")
+	})
+
+	t.Run("long lines are folded", func(t *testing.T) {
+		var buf bytes.Buffer
+		ics := &icsWriter{w: &buf}
+		ics.line("SUMMARY:" + strings.Repeat("é", 50))
+		assert.NoError(t, ics.err)
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+		assert.Len(t, lines, 2)
+
+		for _, line := range lines {
+			assert.LessOrEqual(t, len(line), icsLineLength)
+		}
+
+		assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 50), lines[0]+strings.TrimPrefix(lines[1], " "))
+	})
+}
@@ -0,0 +1,296 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+// tableWriter writes rows of the columns as csv with a header row or as a json object per line
+type tableWriter struct {
+	columns []string
+	format  string
+
+	w      io.Writer
+	writer *csv.Writer
+}
+
+func newTableWriter(w io.Writer, format string, columns ...string) (*tableWriter, error) {
+	t := &tableWriter{columns: columns, format: format, w: w}
+
+	switch format {
+	case exportFormatCSV, "":
+		t.writer = csv.NewWriter(w)
+		if err := t.writer.Write(columns); err != nil {
+			return nil, err
+		}
+	case exportFormatNDJSON:
+	default:
+		return nil, fmt.Errorf("unknown format %q (use csv or ndjson)", format)
+	}
+
+	return t, nil
+}
+
+// write writes a row with a value per column, ndjson keeps the column order and the value types
+func (t *tableWriter) write(values ...any) error {
+	if t.writer != nil {
+		record := make([]string, len(values))
+		for idx, value := range values {
+			record[idx] = fmt.Sprint(value)
+		}
+
+		return t.writer.Write(record)
+	}
+
+	line := bytes.NewBufferString("{")
+	for idx, value := range values {
+		if idx > 0 {
+			line.WriteString(",")
+		}
+
+		valueBytes, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		line.WriteString(strconv.Quote(t.columns[idx]))
+		line.WriteString(":")
+		line.Write(valueBytes)
+	}
+
+	line.WriteString("}\n")
+
+	_, err := t.w.Write(line.Bytes())
+
+	return err
+}
+
+func (t *tableWriter) flush() error {
+	if t.writer == nil {
+		return nil
+	}
+
+	t.writer.Flush()
+
+	return t.writer.Error()
+}
+
+// inRange is true when the storage time is within From (inclusive) and To (exclusive) of the options
+func (o ExportOptions) inRange(ts string) bool {
+	if !o.From.IsZero() && ts < o.From.Format(StorageTimeFmt) {
+		return false
+	}
+
+	if !o.To.IsZero() && ts >= o.To.Format(StorageTimeFmt) {
+		return false
+	}
+
+	return true
+}
+
+// exportBookmarkTime returns the created time of the bookmark, the modified time when it was not created or empty
+// when it has neither e.g. a NULL DateCreated in the Kobo database is the zero time
+func exportBookmarkTime(bookmark StorageBookmark) string {
+	for _, ts := range []string{bookmark.Created, bookmark.Modified} {
+		if t, err := time.Parse(StorageTimeFmt, ts); err == nil && !t.IsZero() {
+			return ts
+		}
+	}
+
+	return ""
+}
+
+// exportContents returns the contents sorted by ID
+func exportContents(storage Storage) []StorageContent {
+	contents := storage.Contents()
+
+	sort.Slice(contents, func(i, j int) bool {
+		return contents[i].ID < contents[j].ID
+	})
+
+	return contents
+}
+
+// ExportSessionsTable writes a row per reading session as stored (before corrections) with the content, device, start,
+// end and duration in seconds
+func ExportSessionsTable(storage Storage, w io.Writer, options ExportOptions) error {
+	type session struct {
+		content StorageContent
+		event   StorageEvents
+	}
+
+	sessions := make([]session, 0)
+
+	for _, content := range storage.Contents() {
+		for _, event := range storage.Events(content.ID) {
+			if event.EventName == ReadEvent.String() && options.inRange(event.Time) {
+				sessions = append(sessions, session{content: content, event: event})
+			}
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].event.Time != sessions[j].event.Time {
+			return sessions[i].event.Time < sessions[j].event.Time
+		}
+
+		return sessions[i].content.ID < sessions[j].content.ID
+	})
+
+	table, err := newTableWriter(w, options.Format, "content_id", "title", "author", "device", "start", "end",
+		"duration", "is_book")
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		end := ""
+		if start, err := time.Parse(StorageTimeFmt, s.event.Time); err == nil {
+			end = start.Add(time.Duration(s.event.Duration) * time.Second).Format(StorageTimeFmt)
+		}
+
+		err := table.write(s.content.ID, s.content.Title, s.content.Author, s.event.Device, s.event.Time, end,
+			s.event.Duration, s.content.IsBook)
+		if err != nil {
+			return err
+		}
+	}
+
+	return table.flush()
+}
+
+// ExportContentsTable writes a row per content. With a time range only the content with a session in it is written
+func ExportContentsTable(storage Storage, w io.Writer, options ExportOptions) error {
+	table, err := newTableWriter(w, options.Format, "content_id", "title", "author", "url", "isbn", "words",
+		"is_book", "is_finished", "rating", "read_count")
+	if err != nil {
+		return err
+	}
+
+	for _, content := range exportContents(storage) {
+		if !options.From.IsZero() || !options.To.IsZero() {
+			found := false
+			for _, event := range storage.Events(content.ID) {
+				if event.EventName == ReadEvent.String() && options.inRange(event.Time) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				continue
+			}
+		}
+
+		err := table.write(content.ID, content.Title, content.Author, content.URL, content.ISBN, content.Words,
+			content.IsBook, content.IsFinished, content.Rating, content.ReadCount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return table.flush()
+}
+
+// ExportBookmarksTable writes a row per highlight, note and dogear created in the time range. Bookmarks without a
+// created time use the modified time and bookmarks without either are always written
+func ExportBookmarksTable(storage Storage, w io.Writer, options ExportOptions) error {
+	table, err := newTableWriter(w, options.Format, "bookmark_id", "content_id", "title", "author", "type", "text",
+		"annotation", "created", "modified")
+	if err != nil {
+		return err
+	}
+
+	for _, content := range exportContents(storage) {
+		bookmarks := storage.Bookmarks(content.ID)
+
+		sort.Slice(bookmarks, func(i, j int) bool {
+			if bookmarks[i].Created != bookmarks[j].Created {
+				return bookmarks[i].Created < bookmarks[j].Created
+			}
+
+			return bookmarks[i].ID < bookmarks[j].ID
+		})
+
+		for _, bookmark := range bookmarks {
+			if ts := exportBookmarkTime(bookmark); ts != "" && !options.inRange(ts) {
+				continue
+			}
+
+			err := table.write(bookmark.ID, content.ID, content.Title, content.Author, bookmark.Type, bookmark.Text,
+				bookmark.Annotation, bookmark.Created, bookmark.Modified)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return table.flush()
+}
+
+// ExportShelvesTable writes a row per content on a shelf, deleted shelves and removed content are not written. Shelf
+// membership has no time so the time range is not used
+func ExportShelvesTable(storage Storage, w io.Writer, options ExportOptions) error {
+	table, err := newTableWriter(w, options.Format, "shelf_id", "shelf", "shelf_type", "content_id", "title", "author")
+	if err != nil {
+		return err
+	}
+
+	contents := map[string]StorageContent{}
+	for _, content := range storage.Contents() {
+		contents[content.ID] = content
+	}
+
+	shelves := storage.Shelfs()
+
+	sort.Slice(shelves, func(i, j int) bool {
+		if shelves[i].Name != shelves[j].Name {
+			return shelves[i].Name < shelves[j].Name
+		}
+
+		return shelves[i].ID < shelves[j].ID
+	})
+
+	// Shelf content is by shelf name, a shelf with the same name on another device is the same shelf
+	written := map[string]bool{}
+
+	for _, shelf := range shelves {
+		if shelf.IsDeleted || written[shelf.Name] {
+			continue
+		}
+
+		written[shelf.Name] = true
+
+		shelfContents := storage.ShelfContents(shelf.Name)
+
+		sort.Slice(shelfContents, func(i, j int) bool {
+			return shelfContents[i].ContentID < shelfContents[j].ContentID
+		})
+
+		for _, shelfContent := range shelfContents {
+			if shelfContent.IsDeleted {
+				continue
+			}
+
+			content := contents[shelfContent.ContentID]
+
+			err := table.write(shelf.ID, shelf.Name, shelf.Type, shelfContent.ContentID, content.Title, content.Author)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return table.flush()
+}
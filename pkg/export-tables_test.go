@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTables(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testArticleID = "articles/a"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "0345457684", 550, true, true, 100)
+	storage.AddContent(testArticleID, "Article", "", "test.com", "", 100, false, false, 10)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), 60)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddEvent(testArticleID, testDeviceAID, ReadEvent.String(), time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC), 120)
+
+	storage.AddBookmark("b1", testBookAID, testBookAID, bookmarkTypeNote, "", 0, 0, 0, "Text, with comma", "Note",
+		time.Date(2024, 1, 1, 20, 5, 0, 0, time.UTC), time.Date(2024, 1, 1, 20, 6, 0, 0, time.UTC))
+
+	storage.AddShelf("shelf-id", "Science Fiction", "Science Fiction", "UserTag", false)
+	storage.AddShelfContent("Science Fiction", testBookAID, false)
+	storage.AddShelfContent("Science Fiction", testArticleID, true)
+
+	year := ExportOptions{From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name     string
+		export   exporter
+		options  ExportOptions
+		expected string
+	}{
+		{
+			name:    "sessions csv in range",
+			export:  ExportSessionsTable,
+			options: year,
+			expected: "content_id,title,author,device,start,end,duration,is_book\n" +
+				testBookAID + ",Altered Carbon,Richard K. Morgan,test-device-a,2024-01-01T20:00:00.000,2024-01-01T20:10:00.000,600,true\n",
+		},
+		{
+			name:    "sessions ndjson",
+			export:  ExportSessionsTable,
+			options: ExportOptions{Format: exportFormatNDJSON, To: year.From},
+			expected: `{"content_id":"articles/a","title":"Article","author":"","device":"test-device-a","start":"2023-06-01T08:00:00.000","end":"2023-06-01T08:02:00.000","duration":120,"is_book":false}` + "\n" +
+				`{"content_id":"` + testBookAID + `","title":"Altered Carbon","author":"Richard K. Morgan","device":"test-device-a","start":"2023-12-31T20:00:00.000","end":"2023-12-31T20:01:00.000","duration":60,"is_book":true}` + "\n",
+		},
+		{
+			name:    "contents with a session in range",
+			export:  ExportContentsTable,
+			options: year,
+			expected: "content_id,title,author,url,isbn,words,is_book,is_finished,rating,read_count\n" +
+				testBookAID + ",Altered Carbon,Richard K. Morgan,,0345457684,550,true,true,0,0\n",
+		},
+		{
+			name:    "bookmarks",
+			export:  ExportBookmarksTable,
+			options: ExportOptions{},
+			expected: "bookmark_id,content_id,title,author,type,text,annotation,created,modified\n" +
+				"b1," + testBookAID + ",Altered Carbon,Richard K. Morgan,note,\"Text, with comma\",Note,2024-01-01T20:05:00.000,2024-01-01T20:06:00.000\n",
+		},
+		{
+			name:    "shelves without removed content",
+			export:  ExportShelvesTable,
+			options: ExportOptions{Format: exportFormatNDJSON},
+			expected: `{"shelf_id":"shelf-id","shelf":"Science Fiction","shelf_type":"UserTag","content_id":"` + testBookAID +
+				`","title":"Altered Carbon","author":"Richard K. Morgan"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			assert.NoError(t, tt.export(storage, &buf, tt.options))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("csv is valid", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		assert.NoError(t, Export(storage, ExportBookmarks, &buf, ExportOptions{}))
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+	})
+
+	t.Run("bookmarks without created time", func(t *testing.T) {
+		undated := NewCopyOnWriteStorage(storage)
+		undated.AddBookmark("b2", testBookAID, testBookAID, bookmarkTypeHighlight, "", 0, 0, 0, "Modified in range", "",
+			time.Time{}, time.Date(2024, 2, 1, 20, 0, 0, 0, time.UTC))
+		undated.AddBookmark("b3", testBookAID, testBookAID, bookmarkTypeHighlight, "", 0, 0, 0, "Modified before", "",
+			time.Time{}, time.Date(2023, 2, 1, 20, 0, 0, 0, time.UTC))
+		undated.AddBookmark("b4", testBookAID, testBookAID, bookmarkTypeDogear, "", 0, 0, 0, "", "", time.Time{}, time.Time{})
+
+		buf := bytes.Buffer{}
+		assert.NoError(t, ExportBookmarksTable(undated, &buf, year))
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 4)
+
+		ids := []string{}
+		for _, row := range rows[1:] {
+			ids = append(ids, row[0])
+		}
+
+		assert.ElementsMatch(t, []string{"b1", "b2", "b4"}, ids)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, ExportSessionsTable(storage, &bytes.Buffer{}, ExportOptions{Format: "xml"}))
+	})
+}
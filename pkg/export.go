@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExportTarget is what the storage can be exported as e.g. a Goodreads import csv
+type ExportTarget string
+
+const (
+	ExportGoodreads ExportTarget = "goodreads"
+	ExportSessions  ExportTarget = "sessions"
+	ExportContents  ExportTarget = "contents"
+	ExportBookmarks ExportTarget = "bookmarks"
+	ExportShelves   ExportTarget = "shelves"
+	ExportICS       ExportTarget = "ics"
+)
+
+var ErrUnknownExportTarget = errors.New("unknown export target")
+
+// ExportOptions are the options of the table exports
+type ExportOptions struct {
+	// Format csv (default) or ndjson
+	Format string
+
+	// From and To limit the sessions, bookmarks and calendar events to the time range, zero is unlimited. Content is exported when
+	// it has a session in the range
+	From time.Time
+	To   time.Time
+
+	// Stats are the options of the stats of the Goodreads and calendar exports, zero is DefaultStatsOptions
+	Stats StatsOptions
+}
+
+// stats returns the stats of the storage with the stats options
+func (o ExportOptions) stats(storage Storage) Stats {
+	if o.Stats == (StatsOptions{}) {
+		return NewStatsWithOptions(storage, DefaultStatsOptions)
+	}
+
+	return NewStatsWithOptions(storage, o.Stats)
+}
+
+// exporter writes the storage to w
+type exporter func(storage Storage, w io.Writer, options ExportOptions) error
+
+var exporters = map[ExportTarget]exporter{
+	ExportGoodreads: ExportGoodreadsCSV,
+	ExportSessions:  ExportSessionsTable,
+	ExportContents:  ExportContentsTable,
+	ExportBookmarks: ExportBookmarksTable,
+	ExportShelves:   ExportShelvesTable,
+	ExportICS:       ExportICSCalendar,
+}
+
+// ExportTargets returns the names of the supported export targets
+func ExportTargets() []string {
+	result := make([]string, 0, len(exporters))
+	for target := range exporters {
+		result = append(result, string(target))
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// ParseExportTarget returns the export target by name e.g. "goodreads"
+func ParseExportTarget(name string) (ExportTarget, error) {
+	target := ExportTarget(strings.ToLower(name))
+	if _, exists := exporters[target]; !exists {
+		return "", fmt.Errorf("%w: %s (use %s)", ErrUnknownExportTarget, name, strings.Join(ExportTargets(), ", "))
+	}
+
+	return target, nil
+}
+
+// Export writes the storage as the target to w
+func Export(storage Storage, target ExportTarget, w io.Writer, options ExportOptions) error {
+	exp, exists := exporters[target]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownExportTarget, target)
+	}
+
+	return exp(storage, w, options)
+}
+
+// shelvesByContent returns the names of the shelves (not deleted) of each canonical content ID
+func shelvesByContent(storage Storage, identity ContentIdentity) map[string][]string {
+	result := map[string][]string{}
+
+	for _, shelf := range storage.Shelfs() {
+		if shelf.IsDeleted {
+			continue
+		}
+
+		for _, shelfContent := range storage.ShelfContents(shelf.Name) {
+			if shelfContent.IsDeleted {
+				continue
+			}
+
+			cid := identity.Canonical(shelfContent.ContentID)
+			if !slices.Contains(result[cid], shelf.Name) {
+				result[cid] = append(result[cid], shelf.Name)
+			}
+		}
+	}
+
+	for cid := range result {
+		sort.Strings(result[cid])
+	}
+
+	return result
+}
@@ -0,0 +1,316 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/timchurchard/kobo-readstat/internal"
+)
+
+const (
+	// HardcoverTokenEnv is the environment variable with the hardcover.app API token when not in the config file
+	HardcoverTokenEnv = "HARDCOVER_TOKEN"
+
+	hardcoverDateFmt = "2006-01-02"
+)
+
+var ErrHardcoverNoToken = errors.New("hardcover API token not found (set token in the config file or " + HardcoverTokenEnv + ")")
+
+// HardcoverConfig is the hardcover.app config file e.g. {"token": "Bearer ..."}
+type HardcoverConfig struct {
+	Token    string `json:"token"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// HardcoverCache maps content IDs (every alias of a book) to hardcover.app book IDs so books are only matched once
+type HardcoverCache struct {
+	Books map[string]int `json:"books"`
+
+	fn string
+}
+
+// HardcoverResult is what HardcoverSync changed
+type HardcoverResult struct {
+	// Updated are the titles of the books with new or changed dates read or status
+	Updated []string `json:"updated"`
+
+	// UpToDate is the number of matched books without changes
+	UpToDate int `json:"up_to_date"`
+
+	// Unmatched are the titles of the books not found on hardcover.app
+	Unmatched []string `json:"unmatched"`
+}
+
+// DefaultHardcoverConfigPath returns the config file in the user config directory e.g. ~/.config/kobo-readstat/hardcover.json
+func DefaultHardcoverConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "hardcover.json"
+	}
+
+	return filepath.Join(dir, "kobo-readstat", "hardcover.json")
+}
+
+// LoadHardcoverConfig reads the config file if it exists, the token falls back to the HardcoverTokenEnv variable
+func LoadHardcoverConfig(fn string) (HardcoverConfig, error) {
+	config := HardcoverConfig{}
+
+	if configBytes, err := os.ReadFile(fn); err == nil {
+		if err := json.Unmarshal(configBytes, &config); err != nil {
+			return config, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return config, err
+	}
+
+	if config.Token == "" {
+		config.Token = os.Getenv(HardcoverTokenEnv)
+	}
+
+	if config.Token == "" {
+		return config, ErrHardcoverNoToken
+	}
+
+	return config, nil
+}
+
+// OpenHardcoverCache reads the cache file or returns an empty cache if it does not exist
+func OpenHardcoverCache(fn string) (*HardcoverCache, error) {
+	cache := &HardcoverCache{Books: map[string]int{}, fn: fn}
+
+	if cacheBytes, err := os.ReadFile(fn); err == nil {
+		if err := json.Unmarshal(cacheBytes, cache); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if cache.Books == nil {
+		cache.Books = map[string]int{}
+	}
+
+	return cache, nil
+}
+
+func (c *HardcoverCache) Save() error {
+	cacheBytes, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.fn, cacheBytes, 0o644)
+}
+
+// set caches the hardcover.app book ID of the content IDs
+func (c *HardcoverCache) set(ids []string, bookID int) {
+	for _, id := range ids {
+		c.Books[id] = bookID
+	}
+}
+
+// HardcoverSync matches the books with reading to hardcover.app books by ISBN or title and author and adds the dates
+// read of each read-through. Existing dates read with the same start are updated when the read-through has since
+// finished, other dates read are never changed. With dryRun nothing is written to hardcover.app.
+func HardcoverSync(storage Storage, client *internal.HardcoverClient, cache *HardcoverCache, dryRun bool) (HardcoverResult, error) {
+	result := HardcoverResult{Updated: make([]string, 0), Unmatched: make([]string, 0)}
+
+	stats := NewStats(storage)
+
+	books := make([]StatsBook, 0, len(stats.Content))
+	for _, book := range stats.Content {
+		if book.IsBook && len(book.ReadThroughs) > 0 {
+			books = append(books, book)
+		}
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		return books[i].BookID < books[j].BookID
+	})
+
+	for _, book := range books {
+		bookID, err := hardcoverMatch(client, cache, book)
+		if err != nil {
+			return result, err
+		}
+
+		if bookID == 0 {
+			result.Unmatched = append(result.Unmatched, book.Title)
+			continue
+		}
+
+		status := internal.HardcoverStatusCurrentlyReading
+		if book.IsFinished {
+			status = internal.HardcoverStatusRead
+		}
+
+		userBook, err := client.UserBook(bookID)
+		if err != nil {
+			return result, err
+		}
+
+		existing := make([]internal.HardcoverUserBookRead, 0)
+		statusChanged := userBook == nil || (status == internal.HardcoverStatusRead && userBook.StatusID != status)
+
+		if userBook != nil {
+			existing = userBook.Reads
+		}
+
+		datesRead := hardcoverDatesRead(book, existing)
+
+		if !statusChanged && len(datesRead) == 0 {
+			result.UpToDate++
+			continue
+		}
+
+		result.Updated = append(result.Updated, book.Title)
+
+		if dryRun {
+			continue
+		}
+
+		userBookID := 0
+		if userBook == nil {
+			if userBookID, err = client.InsertUserBook(bookID, status); err != nil {
+				return result, err
+			}
+		} else {
+			userBookID = userBook.ID
+
+			if statusChanged {
+				if err := client.UpdateUserBookStatus(userBookID, status); err != nil {
+					return result, err
+				}
+			}
+		}
+
+		if len(datesRead) > 0 {
+			if err := client.UpsertDatesRead(userBookID, datesRead); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// hardcoverMatch returns the cached hardcover.app book ID, or finds it by ISBN then by title and author. 0 if not found.
+// The cache is keyed on every alias of the book so a match is kept when another alias becomes the canonical content ID
+func hardcoverMatch(client *internal.HardcoverClient, cache *HardcoverCache, book StatsBook) (int, error) {
+	aliases := book.Aliases
+	if len(aliases) == 0 {
+		aliases = []string{book.BookID}
+	}
+
+	for _, id := range aliases {
+		if bookID, exists := cache.Books[id]; exists {
+			cache.set(aliases, bookID)
+			return bookID, nil
+		}
+	}
+
+	bookID := 0
+
+	if isbn := normaliseISBN(book.ISBN); isbn != "" {
+		found, err := client.FindBookByISBN(isbn)
+		if err != nil {
+			return 0, err
+		}
+
+		if found != nil {
+			bookID = found.ID
+		}
+	}
+
+	if bookID == 0 {
+		found, err := client.FindBooksByTitle(book.Title)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, candidate := range found {
+			for _, author := range candidate.Authors {
+				if normaliseAuthor(author) == normaliseAuthor(book.Author) {
+					bookID = candidate.ID
+					break
+				}
+			}
+
+			if bookID != 0 {
+				break
+			}
+		}
+	}
+
+	if bookID != 0 {
+		cache.set(aliases, bookID)
+	}
+
+	return bookID, nil
+}
+
+// hardcoverDatesRead returns the dates read to insert or update for the read-throughs of the book. A read-through is
+// the same as an existing date read with the same start date, or the same finish date
+func hardcoverDatesRead(book StatsBook, existing []internal.HardcoverUserBookRead) []internal.HardcoverDatesRead {
+	result := make([]internal.HardcoverDatesRead, 0)
+
+	for _, readThrough := range book.ReadThroughs {
+		started := hardcoverDate(readThrough.Start)
+		if started == "" {
+			continue
+		}
+
+		finished := ""
+		if readThrough.IsFinished {
+			finished = hardcoverDate(readThrough.FinishedTime)
+		}
+
+		var match *internal.HardcoverUserBookRead
+		for idx := range existing {
+			if (existing[idx].StartedAt != nil && *existing[idx].StartedAt == started) ||
+				(finished != "" && existing[idx].FinishedAt != nil && *existing[idx].FinishedAt == finished) {
+				match = &existing[idx]
+				break
+			}
+		}
+
+		datesRead := internal.HardcoverDatesRead{
+			Action:          "insert",
+			StartedAt:       &started,
+			ReadingFormatID: internal.HardcoverReadingFormatEbook,
+		}
+
+		if finished != "" {
+			datesRead.FinishedAt = &finished
+		}
+
+		if match != nil {
+			if finished == "" || match.FinishedAt != nil {
+				// Already there, finished dates on hardcover.app are never changed
+				continue
+			}
+
+			datesRead.ID = &match.ID
+			datesRead.Action = "update"
+			datesRead.StartedAt = match.StartedAt
+		}
+
+		result = append(result, datesRead)
+	}
+
+	return result
+}
+
+// hardcoverDate returns the date of the storage time e.g. 2024-01-02, empty if not set
+func hardcoverDate(ts string) string {
+	t, err := time.Parse(StorageTimeFmt, ts)
+	if err != nil {
+		return ""
+	}
+
+	return t.Format(hardcoverDateFmt)
+}
@@ -0,0 +1,243 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/timchurchard/kobo-readstat/internal"
+)
+
+// testHardcoverServer is a local stand-in for the hardcover.app GraphQL API with one user
+type testHardcoverServer struct {
+	books     map[string]int // title or isbn to book ID
+	userBooks map[int]*internal.HardcoverUserBook
+	calls     map[string]int
+	nextID    int
+}
+
+func (s *testHardcoverServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.calls[req.OperationName]++
+
+	bookJSON := func(id int, title string) map[string]any {
+		return map[string]any{"id": id, "title": title, "contributions": []any{
+			map[string]any{"author": map[string]any{"name": "Richard K. Morgan"}},
+		}}
+	}
+
+	var data any
+
+	switch req.OperationName {
+	case "FindBookByISBN":
+		editions := []any{}
+		if id, exists := s.books[req.Variables["isbn"].(string)]; exists {
+			editions = append(editions, map[string]any{"book": bookJSON(id, "")})
+		}
+
+		data = map[string]any{"editions": editions}
+
+	case "FindBookByTitle":
+		books := []any{}
+		if id, exists := s.books[req.Variables["title"].(string)]; exists {
+			books = append(books, bookJSON(id, req.Variables["title"].(string)))
+		}
+
+		data = map[string]any{"books": books}
+
+	case "UserBook":
+		userBooks := []any{}
+		if userBook, exists := s.userBooks[int(req.Variables["bookId"].(float64))]; exists {
+			userBooks = append(userBooks, userBook)
+		}
+
+		data = map[string]any{"me": []any{map[string]any{"user_books": userBooks}}}
+
+	case "InsertUserBook":
+		s.nextID++
+		s.userBooks[int(req.Variables["bookId"].(float64))] = &internal.HardcoverUserBook{
+			ID:       s.nextID,
+			StatusID: int(req.Variables["statusId"].(float64)),
+		}
+
+		data = map[string]any{"insert_user_book": map[string]any{"id": s.nextID}}
+
+	case "UpdateUserBook":
+		for _, userBook := range s.userBooks {
+			if userBook.ID == int(req.Variables["id"].(float64)) {
+				userBook.StatusID = int(req.Variables["statusId"].(float64))
+			}
+		}
+
+		data = map[string]any{"update_user_book": map[string]any{"id": req.Variables["id"]}}
+
+	case "UpsertDatesRead":
+		datesBytes, _ := json.Marshal(req.Variables["datesRead"])
+
+		var datesRead []internal.HardcoverDatesRead
+		_ = json.Unmarshal(datesBytes, &datesRead)
+
+		for _, userBook := range s.userBooks {
+			if userBook.ID != int(req.Variables["userBookId"].(float64)) {
+				continue
+			}
+
+			for _, dates := range datesRead {
+				if dates.ID == nil {
+					s.nextID++
+					userBook.Reads = append(userBook.Reads, internal.HardcoverUserBookRead{ID: s.nextID, StartedAt: dates.StartedAt, FinishedAt: dates.FinishedAt})
+					continue
+				}
+
+				for idx := range userBook.Reads {
+					if userBook.Reads[idx].ID == *dates.ID {
+						userBook.Reads[idx].FinishedAt = dates.FinishedAt
+					}
+				}
+			}
+		}
+
+		data = map[string]any{"upsert_user_book_reads": map[string]any{"error": nil}}
+
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": []any{map[string]any{"message": "unknown operation"}}})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func TestHardcoverSync(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "books/altered-carbon.epub"
+		testBookBID   = "books/broken-angels.epub"
+		testBookCID   = "books/unknown.epub"
+	)
+
+	hardcover := &testHardcoverServer{
+		books: map[string]int{
+			"9780345457684": 100,
+			"Broken Angels": 200,
+		},
+		userBooks: map[int]*internal.HardcoverUserBook{},
+		calls:     map[string]int{},
+	}
+
+	server := httptest.NewServer(hardcover)
+	defer server.Close()
+
+	client := internal.NewHardcoverClient(server.URL, "test-token")
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "0345457684", 100, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC), 0)
+
+	storage.AddContent(testBookBID, "Broken Angels", "Richard K. Morgan", "", "", 100, true, false, 10)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 2, 1, 20, 0, 0, 0, time.UTC), 600)
+
+	storage.AddContent(testBookCID, "Unknown", "Nobody", "", "", 100, true, false, 10)
+	storage.AddEvent(testBookCID, testDeviceAID, ReadEvent.String(), time.Date(2024, 2, 1, 21, 0, 0, 0, time.UTC), 600)
+
+	cache, err := OpenHardcoverCache(filepath.Join(t.TempDir(), "hardcover_cache.json"))
+	assert.NoError(t, err)
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := HardcoverSync(storage, client, cache, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Altered Carbon", "Broken Angels"}, result.Updated)
+		assert.Equal(t, []string{"Unknown"}, result.Unmatched)
+		assert.Empty(t, hardcover.userBooks)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		result, err := HardcoverSync(storage, client, cache, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Altered Carbon", "Broken Angels"}, result.Updated)
+
+		assert.Equal(t, map[string]int{testBookAID: 100, testBookBID: 200}, cache.Books)
+
+		assert.Equal(t, internal.HardcoverStatusRead, hardcover.userBooks[100].StatusID)
+		assert.Len(t, hardcover.userBooks[100].Reads, 1)
+		assert.Equal(t, "2024-01-01", *hardcover.userBooks[100].Reads[0].StartedAt)
+		assert.Equal(t, "2024-01-05", *hardcover.userBooks[100].Reads[0].FinishedAt)
+
+		assert.Equal(t, internal.HardcoverStatusCurrentlyReading, hardcover.userBooks[200].StatusID)
+		assert.Len(t, hardcover.userBooks[200].Reads, 1)
+		assert.Nil(t, hardcover.userBooks[200].Reads[0].FinishedAt)
+	})
+
+	t.Run("finish and sync again", func(t *testing.T) {
+		storage.AddEvent(testBookBID, testDeviceAID, FinishEvent.String(), time.Date(2024, 2, 3, 20, 0, 0, 0, time.UTC), 0)
+
+		findCalls := hardcover.calls["FindBookByISBN"] + hardcover.calls["FindBookByTitle"]
+
+		result, err := HardcoverSync(storage, client, cache, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Broken Angels"}, result.Updated)
+		assert.Equal(t, 1, result.UpToDate)
+
+		// Matched books are not searched again, only the unmatched book
+		assert.Equal(t, findCalls+1, hardcover.calls["FindBookByISBN"]+hardcover.calls["FindBookByTitle"])
+
+		assert.Equal(t, internal.HardcoverStatusRead, hardcover.userBooks[200].StatusID)
+		assert.Len(t, hardcover.userBooks[200].Reads, 1)
+		assert.Equal(t, "2024-02-03", *hardcover.userBooks[200].Reads[0].FinishedAt)
+	})
+
+	t.Run("new alias uses the cached match", func(t *testing.T) {
+		const testBookDID = "a/altered-carbon.kepub.epub"
+
+		storage.AddContent(testBookDID, "Altered Carbon", "Richard K. Morgan", "", "", 100, true, false, 10)
+
+		findCalls := hardcover.calls["FindBookByISBN"] + hardcover.calls["FindBookByTitle"]
+
+		result, err := HardcoverSync(storage, client, cache, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.UpToDate)
+
+		// Only the unmatched book is searched
+		assert.Equal(t, findCalls+1, hardcover.calls["FindBookByISBN"]+hardcover.calls["FindBookByTitle"])
+		assert.Equal(t, 100, cache.Books[testBookDID])
+	})
+
+	t.Run("bad token", func(t *testing.T) {
+		_, err := HardcoverSync(storage, internal.NewHardcoverClient(server.URL, "wrong"), cache, false)
+		assert.ErrorIs(t, err, internal.ErrHardcover)
+	})
+}
+
+func TestLoadHardcoverConfig(t *testing.T) {
+	t.Setenv(HardcoverTokenEnv, "")
+
+	_, err := LoadHardcoverConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, ErrHardcoverNoToken)
+
+	t.Setenv(HardcoverTokenEnv, "env-token")
+
+	config, err := LoadHardcoverConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "env-token", config.Token)
+}
@@ -0,0 +1,239 @@
+package pkg
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ContentIdentity relates content that is the same book on different devices or paths. Books are matched on ISBN,
+// normalised title and author, or Kobo volume ID and every match is an alias of one canonical content ID.
+type ContentIdentity struct {
+	// canonical maps every known content ID to the canonical content ID
+	canonical map[string]string
+
+	// aliases maps the canonical content ID to every content ID that is the same book (including itself)
+	aliases map[string][]string
+
+	// volumes maps the normalised volume ID to the canonical content ID
+	volumes map[string]string
+}
+
+// NewContentIdentity groups the contents that are the same book. The canonical content ID of each group is the content
+// added first (content stored before the added time was recorded is the oldest), then content synced from a Kobo over
+// imported or logged content, then the smallest content ID. A new alias is added later than the content already known,
+// so it never moves the canonical ID and everything keyed on it e.g. site pages and calendar UIDs
+func NewContentIdentity(contents []StorageContent) ContentIdentity {
+	result := ContentIdentity{
+		canonical: make(map[string]string),
+		aliases:   make(map[string][]string),
+		volumes:   make(map[string]string),
+	}
+
+	parent := make([]int, len(contents))
+	for idx := range parent {
+		parent[idx] = idx
+	}
+
+	var find func(idx int) int
+	find = func(idx int) int {
+		if parent[idx] != idx {
+			parent[idx] = find(parent[idx])
+		}
+
+		return parent[idx]
+	}
+
+	firstByKey := map[string]int{}
+	for idx := range contents {
+		for _, key := range identityKeys(contents[idx]) {
+			first, exists := firstByKey[key]
+			if !exists {
+				firstByKey[key] = idx
+				continue
+			}
+
+			parent[find(idx)] = find(first)
+		}
+	}
+
+	groups := map[int][]StorageContent{}
+	for idx := range contents {
+		root := find(idx)
+		groups[root] = append(groups[root], contents[idx])
+	}
+
+	for _, group := range groups {
+		canonical := group[0]
+		ids := make([]string, 0, len(group))
+
+		for _, content := range group {
+			if isCanonicalBefore(content, canonical) {
+				canonical = content
+			}
+
+			ids = append(ids, content.ID)
+		}
+
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			result.canonical[id] = canonical.ID
+			result.volumes[normaliseVolumeID(id)] = canonical.ID
+		}
+
+		result.aliases[canonical.ID] = ids
+	}
+
+	return result
+}
+
+// isCanonicalBefore is true when content a is preferred as the canonical content of a group over b
+func isCanonicalBefore(a, b StorageContent) bool {
+	if a.Added != b.Added {
+		return a.Added < b.Added
+	}
+
+	if aImported, bImported := isImportedContentID(a.ID), isImportedContentID(b.ID); aImported != bImported {
+		return !aImported
+	}
+
+	return a.ID < b.ID
+}
+
+// isImportedContentID is true for content from an import or the log command rather than synced from a Kobo
+func isImportedContentID(id string) bool {
+	for _, prefix := range []string{goodreadsContentPrefix, storyGraphContentPrefix, kindleContentPrefix, manualContentPrefix} {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Canonical returns the canonical content ID, unknown IDs are matched on volume ID or returned unchanged
+func (i ContentIdentity) Canonical(id string) string {
+	if cid, exists := i.canonical[id]; exists {
+		return cid
+	}
+
+	if cid, exists := i.volumes[normaliseVolumeID(id)]; exists {
+		return cid
+	}
+
+	return id
+}
+
+// Aliases returns every content ID that is the same book as id (including the canonical ID)
+func (i ContentIdentity) Aliases(id string) []string {
+	if aliases, exists := i.aliases[i.Canonical(id)]; exists {
+		return aliases
+	}
+
+	return []string{id}
+}
+
+// identityKeys returns the keys content can be matched on. Only books are matched on ISBN and title/author
+func identityKeys(content StorageContent) []string {
+	result := []string{"volume:" + normaliseVolumeID(content.ID)}
+
+	if !content.IsBook {
+		return result
+	}
+
+	if isbn := normaliseISBN(content.ISBN); isbn != "" {
+		result = append(result, "isbn:"+isbn)
+	}
+
+	title := normaliseTitle(content.Title)
+	author := normaliseAuthor(content.Author)
+
+	if title != "" && author != "" {
+		result = append(result, "title:"+title+"|"+author)
+	}
+
+	return result
+}
+
+// normaliseVolumeID takes a Kobo volume ID e.g. "file:///mnt/onboard/dir/file.epub" and returns "dir/file.epub"
+func normaliseVolumeID(id string) string {
+	fn, _ := splitContentFilename(id)
+
+	return strings.ToLower(strings.TrimPrefix(fn, KoboFilenamePrefix))
+}
+
+// normaliseISBN returns the ISBN-13 digits or empty if isbn is not a valid length
+func normaliseISBN(isbn string) string {
+	digits := strings.Builder{}
+
+	for _, r := range strings.ToUpper(isbn) {
+		if unicode.IsDigit(r) || r == 'X' {
+			digits.WriteRune(r)
+		}
+	}
+
+	result := digits.String()
+
+	switch len(result) {
+	case 13:
+		if strings.Contains(result, "X") {
+			return ""
+		}
+
+		return result
+
+	case 10:
+		// ISBN-10 to ISBN-13 is 978 + first 9 digits + new check digit
+		result = "978" + result[:9]
+
+		sum := 0
+		for idx, r := range result {
+			weight := 1
+			if idx%2 == 1 {
+				weight = 3
+			}
+
+			sum += int(r-'0') * weight
+		}
+
+		return result + string(rune('0'+(10-sum%10)%10))
+	}
+
+	return ""
+}
+
+// normaliseTitle lower cases, removes punctuation and a leading article e.g. "The Green Mile" is "green mile"
+func normaliseTitle(title string) string {
+	words := normaliseWords(title)
+
+	if len(words) > 1 {
+		switch words[0] {
+		case "the", "a", "an":
+			words = words[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// normaliseAuthor lower cases, removes punctuation and initials and sorts the names so "Liu Cixin" and "Cixin Liu" match
+func normaliseAuthor(author string) string {
+	words := make([]string, 0)
+
+	for _, word := range normaliseWords(author) {
+		if len([]rune(word)) > 1 {
+			words = append(words, word)
+		}
+	}
+
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
+
+func normaliseWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContentIdentity(t *testing.T) {
+	contents := []StorageContent{
+		{ID: "Morgan, Richard/Altered Carbon.kepub.epub", Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true},
+		{ID: "books/altered-carbon.epub", Title: "Altered Carbon", Author: "Richard K. K. Morgan", IsBook: true},
+		{ID: "0d6f5a8c-1111-2222-3333-444455556666", Title: "The Dark Forest", Author: "Cixin Liu", ISBN: "9780765386694", IsBook: true},
+		{ID: "sideloaded/dark-forest.epub", Title: "The Three-Body Problem, No. 2: Dark Forest", Author: "Liu Cixin", ISBN: "978-0-7653-8669-4", IsBook: true},
+		{ID: "books/other.epub", Title: "Altered Carbon", Author: "Someone Else", IsBook: true},
+		{ID: "articles/a", Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: false},
+	}
+
+	identity := NewContentIdentity(contents)
+
+	assert.Equal(t, "Morgan, Richard/Altered Carbon.kepub.epub", identity.Canonical("books/altered-carbon.epub"))
+	assert.Equal(t, "0d6f5a8c-1111-2222-3333-444455556666", identity.Canonical("sideloaded/dark-forest.epub"))
+	assert.Equal(t, "books/other.epub", identity.Canonical("books/other.epub"))
+	assert.Equal(t, "articles/a", identity.Canonical("articles/a"))
+	assert.Equal(t, "Morgan, Richard/Altered Carbon.kepub.epub", identity.Canonical("file:///mnt/onboard/books/altered-carbon.epub"))
+	assert.Equal(t, "unknown", identity.Canonical("unknown"))
+
+	assert.Len(t, identity.Aliases("books/altered-carbon.epub"), 2)
+	assert.Len(t, identity.Aliases("unknown"), 1)
+}
+
+func TestContentIdentityCanonicalIsStable(t *testing.T) {
+	const (
+		testBookAID = "file:///mnt/onboard/books/altered-carbon.epub"
+		testBookBID = "0d6f5a8c-1111-2222-3333-444455556666"
+	)
+
+	contents := []StorageContent{
+		{ID: testBookAID, Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true, Added: "2024-01-01T10:00:00.000"},
+	}
+	assert.Equal(t, testBookAID, NewContentIdentity(contents).Canonical(testBookAID))
+
+	// A later alias that sorts first does not move the canonical ID
+	contents = append(contents,
+		StorageContent{ID: testBookBID, Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true, Added: "2024-06-01T10:00:00.000"},
+		StorageContent{ID: "goodreads/123", Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true, Added: "2024-07-01T10:00:00.000"},
+	)
+
+	identity := NewContentIdentity(contents)
+	assert.Equal(t, testBookAID, identity.Canonical(testBookBID))
+	assert.Equal(t, testBookAID, identity.Canonical("goodreads/123"))
+	assert.Len(t, identity.Aliases(testBookAID), 3)
+
+	// Without added times synced content is preferred over imported content, then the smallest ID
+	identity = NewContentIdentity([]StorageContent{
+		{ID: "goodreads/123", Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true},
+		{ID: testBookAID, Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true},
+		{ID: testBookBID, Title: "Altered Carbon", Author: "Richard K. Morgan", IsBook: true},
+	})
+	assert.Equal(t, testBookBID, identity.Canonical("goodreads/123"))
+
+	t.Run("storage records when content was added", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 0, true, false, 0)
+		added := storage.Contents()[0].Added
+		assert.NotEmpty(t, added)
+
+		storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 0, true, true, 100)
+		assert.Equal(t, added, storage.Contents()[0].Added)
+	})
+}
+
+func TestNormaliseISBN(t *testing.T) {
+	assert.Equal(t, "9780765386694", normaliseISBN("978-0-7653-8669-4"))
+	assert.Equal(t, "9780306406157", normaliseISBN("0-306-40615-2"))
+	assert.Equal(t, "", normaliseISBN("12345"))
+}
+
+func TestNewStatsAliases(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent("books/a.epub", "The Green Mile", "Stephen King", "", "", 100, true, false, 50)
+	storage.AddContent("other/green-mile.kepub.epub", "Green Mile", "King, Stephen", "", "", 100, true, true, 100)
+	storage.AddEvent("books/a.epub", "libra", ReadEvent.String(), time.Date(2023, 12, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent("other/green-mile.kepub.epub", "clara", ReadEvent.String(), time.Date(2023, 12, 2, 20, 0, 0, 0, time.UTC), 900)
+	storage.AddEvent("other/green-mile.kepub.epub", "clara", FinishEvent.String(), time.Date(2023, 12, 2, 20, 15, 0, 0, time.UTC), 0)
+
+	// The same highlight synced under both paths
+	highlightTime := time.Date(2023, 12, 1, 20, 5, 0, 0, time.UTC)
+	storage.AddBookmark("bm", "books/a.epub", "books/a.epub", bookmarkTypeHighlight, "", 0, 0, 0, "Text", "", highlightTime, highlightTime)
+	storage.AddBookmark("bm", "other/green-mile.kepub.epub", "other/green-mile.kepub.epub", bookmarkTypeHighlight, "", 0, 0, 0, "Text", "",
+		highlightTime, highlightTime)
+
+	stats := NewStats(storage)
+
+	assert.Len(t, stats.Content, 1)
+
+	finished := stats.BooksFinishedYear(2023)
+	assert.Len(t, finished, 1)
+	assert.Equal(t, "books/a.epub", finished[0].BookID)
+	assert.Equal(t, 1500, finished[0].ReadSeconds())
+	assert.Len(t, finished[0].Aliases, 2)
+	assert.Len(t, finished[0].Bookmarks, 1)
+}
@@ -0,0 +1,326 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	importFormatCSV    = "csv"
+	importFormatNDJSON = "ndjson"
+)
+
+var ErrImportMapping = errors.New("invalid mapping")
+
+// ImportMapping is the column (csv header or ndjson key) of each field of a reading log. Only the title is required.
+// A session is the start and end, or the start or end with the duration. Times are TimeFormat (default the formats
+// of the log command) and a duration is e.g. "45m" or a number of DurationUnit (seconds, minutes or hours, default
+// minutes). e.g. {"title": "Book", "author": "Author", "start": "Date", "duration": "Minutes"}
+type ImportMapping struct {
+	Title    string `json:"title"`
+	Author   string `json:"author,omitempty"`
+	Start    string `json:"start,omitempty"`
+	End      string `json:"end,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Finished string `json:"finished,omitempty"`
+	Words    string `json:"words,omitempty"`
+	Pages    string `json:"pages,omitempty"`
+	Device   string `json:"device,omitempty"`
+
+	TimeFormat   string `json:"time_format,omitempty"`
+	DurationUnit string `json:"duration_unit,omitempty"`
+}
+
+// importRow is a row of a reading log with its line number
+type importRow struct {
+	Line   int
+	Values map[string]string
+}
+
+// LoadImportMapping reads a json mapping file
+func LoadImportMapping(fn string) (ImportMapping, error) {
+	mapping := ImportMapping{}
+
+	mappingBytes, err := os.ReadFile(fn)
+	if err != nil {
+		return mapping, err
+	}
+
+	if err := json.Unmarshal(mappingBytes, &mapping); err != nil {
+		return mapping, fmt.Errorf("%w: %v", ErrImportMapping, err)
+	}
+
+	return mapping, mapping.validate()
+}
+
+func (m ImportMapping) validate() error {
+	if m.Title == "" {
+		return fmt.Errorf("%w: title column is required", ErrImportMapping)
+	}
+
+	switch m.DurationUnit {
+	case "", "seconds", "minutes", "hours":
+	default:
+		return fmt.Errorf("%w: duration_unit %q (use seconds, minutes or hours)", ErrImportMapping, m.DurationUnit)
+	}
+
+	return nil
+}
+
+// ImportReadingLog imports a csv or ndjson reading log using the mapping of the options. Each row is a manual entry,
+// the same as the log command, so sessions are deduplicated like synced sessions. Invalid rows are rejected with the
+// reason and the other rows are imported.
+func ImportReadingLog(storage Storage, r io.Reader, options ImportOptions) (ImportResult, error) {
+	result := ImportResult{Rejected: make([]ImportRejection, 0)}
+
+	mapping := options.Mapping
+	if err := mapping.validate(); err != nil {
+		return result, err
+	}
+
+	var (
+		rows []importRow
+		err  error
+	)
+
+	switch options.Format {
+	case importFormatCSV, "":
+		rows, err = readingLogCSV(r, &result)
+	case importFormatNDJSON:
+		rows, err = readingLogNDJSON(r, &result)
+	default:
+		return result, fmt.Errorf("unknown format %q (use csv or ndjson)", options.Format)
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	contents := map[string]bool{}
+
+	for _, row := range rows {
+		entry, err := mapping.manualEntry(row.Values)
+		if err != nil {
+			result.Rejected = append(result.Rejected, ImportRejection{Row: row.Line, Reason: err.Error()})
+			continue
+		}
+
+		cid, err := AddManualEntry(storage, entry)
+		if err != nil {
+			result.Rejected = append(result.Rejected, ImportRejection{Row: row.Line, Reason: err.Error()})
+			continue
+		}
+
+		contents[cid] = true
+		result.Contents = len(contents)
+
+		if !entry.Finished.IsZero() {
+			result.Finishes++
+		}
+	}
+
+	return result, nil
+}
+
+// manualEntry returns the manual entry of the row
+func (m ImportMapping) manualEntry(values map[string]string) (ManualEntry, error) {
+	entry := ManualEntry{
+		Title:  m.value(values, m.Title),
+		Author: m.value(values, m.Author),
+		Device: m.value(values, m.Device),
+	}
+
+	var err error
+
+	if entry.Words, err = m.int(values, m.Words); err != nil {
+		return entry, fmt.Errorf("words: %w", err)
+	}
+
+	if entry.Pages, err = m.int(values, m.Pages); err != nil {
+		return entry, fmt.Errorf("pages: %w", err)
+	}
+
+	start, err := m.time(values, m.Start)
+	if err != nil {
+		return entry, fmt.Errorf("start: %w", err)
+	}
+
+	end, err := m.time(values, m.End)
+	if err != nil {
+		return entry, fmt.Errorf("end: %w", err)
+	}
+
+	duration, err := m.duration(values, m.Duration)
+	if err != nil {
+		return entry, fmt.Errorf("duration: %w", err)
+	}
+
+	if entry.Finished, err = m.time(values, m.Finished); err != nil {
+		return entry, fmt.Errorf("finished: %w", err)
+	}
+
+	switch {
+	case !start.IsZero() && !end.IsZero():
+		if !end.After(start) {
+			return entry, fmt.Errorf("end %s is not after start %s", m.value(values, m.End), m.value(values, m.Start))
+		}
+
+		entry.Sessions = append(entry.Sessions, ManualSession{Start: start, Duration: end.Sub(start)})
+
+	case !start.IsZero() && duration != 0:
+		entry.Sessions = append(entry.Sessions, ManualSession{Start: start, Duration: duration})
+
+	case !end.IsZero() && duration != 0:
+		entry.Sessions = append(entry.Sessions, ManualSession{Start: end.Add(-duration), Duration: duration})
+
+	case !start.IsZero() || !end.IsZero() || duration != 0:
+		return entry, errors.New("a session needs two of start, end and duration")
+	}
+
+	return entry, nil
+}
+
+// value is the value of the column, empty if the field is not mapped
+func (m ImportMapping) value(values map[string]string, column string) string {
+	if column == "" {
+		return ""
+	}
+
+	return values[column]
+}
+
+func (m ImportMapping) int(values map[string]string, column string) (int, error) {
+	value := m.value(values, column)
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(strings.ReplaceAll(value, ",", ""))
+}
+
+func (m ImportMapping) time(values map[string]string, column string) (time.Time, error) {
+	value := m.value(values, column)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if m.TimeFormat != "" {
+		return time.ParseInLocation(m.TimeFormat, value, time.Local)
+	}
+
+	return ParseManualTime(value)
+}
+
+func (m ImportMapping) duration(values map[string]string, column string) (time.Duration, error) {
+	value := m.value(values, column)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.ParseDuration(value)
+	}
+
+	unit := time.Minute
+	switch m.DurationUnit {
+	case "seconds":
+		unit = time.Second
+	case "hours":
+		unit = time.Hour
+	}
+
+	return time.Duration(number * float64(unit)), nil
+}
+
+// readingLogCSV reads the rows of a csv with a header row. Rows that are not valid csv are rejected
+func readingLogCSV(r io.Reader, result *ImportResult) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []importRow{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // byte order mark
+	}
+
+	rows := make([]importRow, 0)
+
+	for {
+		record, err := reader.Read()
+
+		var parseErr *csv.ParseError
+		if errors.Is(err, io.EOF) {
+			break
+		} else if errors.As(err, &parseErr) {
+			result.Rejected = append(result.Rejected, ImportRejection{Row: parseErr.StartLine, Reason: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		row := importRow{Line: line, Values: make(map[string]string, len(header))}
+		for idx := range header {
+			if idx < len(record) {
+				row.Values[strings.TrimSpace(header[idx])] = strings.TrimSpace(record[idx])
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readingLogNDJSON reads the rows of a file with a json object per line. Lines that are not an object are rejected
+func readingLogNDJSON(r io.Reader, result *ImportResult) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	rows := make([]importRow, 0)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.UseNumber()
+
+		object := map[string]any{}
+		if err := decoder.Decode(&object); err != nil {
+			result.Rejected = append(result.Rejected, ImportRejection{Row: line, Reason: err.Error()})
+			continue
+		}
+
+		row := importRow{Line: line, Values: make(map[string]string, len(object))}
+		for key, value := range object {
+			if value != nil {
+				row.Values[key] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+}
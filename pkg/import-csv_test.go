@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportReadingLog(t *testing.T) {
+	mapping := ImportMapping{
+		Title:    "Book",
+		Author:   "Writer",
+		Start:    "Date",
+		Duration: "Minutes",
+		Finished: "Done",
+		Pages:    "Pages",
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		log    string
+	}{
+		{
+			name:   "csv",
+			format: importFormatCSV,
+			log: `Book,Writer,Date,Minutes,Done,Pages
+The Green Mile,Stephen King,2024-03-01 20:00,45,,400
+The Green Mile,Stephen King,2024-03-01 20:00,45,,400
+The Green Mile,Stephen King,2024-03-02 20:00,1h30m,2024-03-02,400
+,Nobody,2024-03-03 20:00,10,,
+Carrie,Stephen King,yesterday,10,,
+Carrie,Stephen King,2024-03-04 20:00,,,
+`,
+		},
+		{
+			name:   "ndjson",
+			format: importFormatNDJSON,
+			log: `{"Book": "The Green Mile", "Writer": "Stephen King", "Date": "2024-03-01 20:00", "Minutes": 45, "Pages": 400}
+{"Book": "The Green Mile", "Writer": "Stephen King", "Date": "2024-03-01 20:00", "Minutes": 45, "Pages": 400}
+{"Book": "The Green Mile", "Writer": "Stephen King", "Date": "2024-03-02 20:00", "Minutes": "1h30m", "Done": "2024-03-02", "Pages": 400}
+{"Writer": "Nobody", "Date": "2024-03-03 20:00", "Minutes": 10}
+{"Book": "Carrie", "Writer": "Stephen King", "Date": "yesterday", "Minutes": 10}
+{"Book": "Carrie", "Writer": "Stephen King", "Date": "2024-03-04 20:00"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+			assert.NoError(t, err)
+
+			result, err := ImportReadingLog(storage, strings.NewReader(tt.log), ImportOptions{Mapping: mapping, Format: tt.format})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result.Contents)
+			assert.Equal(t, 1, result.Finishes)
+
+			rows := make([]int, 0)
+			for _, rejection := range result.Rejected {
+				rows = append(rows, rejection.Row)
+			}
+
+			// The csv header is line 1
+			offset := 0
+			if tt.format == importFormatCSV {
+				offset = 1
+			}
+
+			assert.Equal(t, []int{4 + offset, 5 + offset, 6 + offset}, rows)
+			assert.Contains(t, result.Rejected[0].Reason, ErrManualNoTitle.Error())
+			assert.Contains(t, result.Rejected[1].Reason, "start")
+			assert.Contains(t, result.Rejected[2].Reason, "two of start, end and duration")
+
+			// The repeated session is only added once
+			cid := ManualContentID("The Green Mile", "Stephen King")
+			assert.Len(t, storage.Events(cid), 3)
+
+			stats := NewStats(storage)
+			assert.Equal(t, 45*60+90*60, stats.BooksSecondsReadMonth(2024, 3))
+			assert.Len(t, stats.BooksFinishedMonth(2024, 3), 1)
+		})
+	}
+}
+
+func TestImportMappingValidate(t *testing.T) {
+	assert.ErrorIs(t, ImportMapping{}.validate(), ErrImportMapping)
+	assert.ErrorIs(t, ImportMapping{Title: "Book", DurationUnit: "days"}.validate(), ErrImportMapping)
+	assert.NoError(t, ImportMapping{Title: "Book", DurationUnit: "hours"}.validate())
+}
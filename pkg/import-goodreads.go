@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	goodreadsModel         = "Goodreads import"
+	goodreadsContentPrefix = "goodreads/"
+	goodreadsShelfType     = "Goodreads"
+
+	// goodreadsReadShelf is the exclusive shelf of finished books
+	goodreadsReadShelf = "read"
+)
+
+// ImportGoodreadsCSV imports a Goodreads library export (goodreads_library_export.csv). Each row is book content
+// "goodreads/<Book Id>" with a Finish event on the Date Read. Shelves are added as shelves. Importing the export again
+// updates the content and replaces the Finish event when the Date Read changed, without duplicating events.
+func ImportGoodreadsCSV(storage Storage, r io.Reader) (ImportResult, error) {
+	records, err := csvRecords(r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	writer := newImportWriter(storage, ImportGoodreads, goodreadsModel, goodreadsShelfType)
+
+	for idx, record := range records {
+		if record["Book Id"] == "" || record["Title"] == "" {
+			return writer.result, fmt.Errorf("row %d: missing Book Id or Title", idx+2)
+		}
+
+		book := importBook{
+			ID:       goodreadsContentPrefix + record["Book Id"],
+			Title:    record["Title"],
+			Author:   record["Author"],
+			ISBN:     goodreadsISBN(record["ISBN13"]),
+			Finished: record["Exclusive Shelf"] == goodreadsReadShelf,
+			Shelves:  splitList(record["Bookshelves"]),
+		}
+
+		if book.ISBN == "" {
+			book.ISBN = goodreadsISBN(record["ISBN"])
+		}
+
+		pages, _ := strconv.Atoi(record["Number of Pages"])
+		book.Words = pages * wordsPerPage
+
+		book.Rating, _ = strconv.Atoi(record["My Rating"])
+		book.ReadCount, _ = strconv.Atoi(record["Read Count"])
+
+		if record["Date Read"] != "" {
+			dateRead, err := ParseManualTime(record["Date Read"])
+			if err != nil {
+				return writer.result, fmt.Errorf("row %d: Date Read: %w", idx+2, err)
+			}
+
+			book.Finishes = []time.Time{dateRead}
+		}
+
+		if exclusive := record["Exclusive Shelf"]; exclusive != "" {
+			book.Shelves = append(book.Shelves, exclusive)
+		}
+
+		writer.addBook(book)
+	}
+
+	return writer.result, nil
+}
+
+// goodreadsISBN returns the ISBN from the spreadsheet formula Goodreads exports e.g. ="9780441012039"
+func goodreadsISBN(s string) string {
+	return strings.Trim(s, `="`)
+}
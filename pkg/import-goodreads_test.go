@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGoodreadsCSV = `Book Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Publisher,Binding,Number of Pages,Year Published,Original Publication Year,Date Read,Date Added,Bookshelves,Bookshelves with positions,Exclusive Shelf,My Review,Spoiler,Private Notes,Read Count,Owned Copies
+40445,"Altered Carbon (Takeshi Kovacs, #1)",Richard K. Morgan,"Morgan, Richard K.",,"=""0345457684""","=""9780345457684""",4,4.09,Del Rey,Paperback,526,2003,2002,2019/08/14,2019/07/01,"sci-fi, favourites","sci-fi (#3), favourites (#1)",read,,,,2,0
+11,Matilda,Roald Dahl,"Dahl, Roald",,"=""""","=""""",0,4.33,Puffin,Paperback,240,1988,1988,,2020/01/01,,,to-read,,,,0,0
+`
+
+func TestImportGoodreadsCSV(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	result, err := ImportGoodreadsCSV(storage, strings.NewReader(testGoodreadsCSV))
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{Contents: 2, Finishes: 1, Shelves: 4}, result)
+
+	// Importing again does not duplicate anything
+	_, err = ImportGoodreadsCSV(storage, strings.NewReader(testGoodreadsCSV))
+	assert.NoError(t, err)
+
+	contents := storage.Contents()
+	assert.Len(t, contents, 2)
+
+	content, err := FindContent(contents, "goodreads/40445")
+	assert.NoError(t, err)
+	assert.Equal(t, "Richard K. Morgan", content.Author)
+	assert.Equal(t, "9780345457684", content.ISBN)
+	assert.Equal(t, 526*wordsPerPage, content.Words)
+	assert.Equal(t, 4, content.Rating)
+	assert.Equal(t, 2, content.ReadCount)
+	assert.True(t, content.IsBook)
+	assert.True(t, content.IsFinished)
+
+	events := storage.Events("goodreads/40445")
+	assert.Len(t, events, 1)
+	assert.Equal(t, FinishEvent.String(), events[0].EventName)
+	assert.Equal(t, "2019-08-14T00:00:00.000", events[0].Time)
+
+	content, err = FindContent(contents, "Matilda")
+	assert.NoError(t, err)
+	assert.False(t, content.IsFinished)
+	assert.Empty(t, storage.Events(content.ID))
+
+	assert.Len(t, storage.ShelfContents("favourites"), 1)
+	assert.Len(t, storage.ShelfContents("read"), 1)
+
+	stats := NewStats(storage)
+	finished2019 := stats.BooksFinishedYear(2019)
+	assert.Len(t, finished2019, 1)
+	assert.Equal(t, 2, finished2019[0].ReadCount)
+	assert.Equal(t, 4, finished2019[0].Rating)
+}
+
+func TestImportGoodreadsCSVChangedDateRead(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	_, err = ImportGoodreadsCSV(storage, strings.NewReader(testGoodreadsCSV))
+	assert.NoError(t, err)
+
+	storage.AddEvent("goodreads/40445", "libra", FinishEvent.String(), time.Date(2019, 8, 1, 20, 0, 0, 0, time.UTC), 0)
+
+	// The Date Read was fixed on Goodreads before exporting again
+	storage.StartSync(string(ImportGoodreads), "goodreads_library_export.csv", "", time.Now())
+	_, err = ImportGoodreadsCSV(storage, strings.NewReader(strings.Replace(testGoodreadsCSV, "2019/08/14", "2019/08/20", 1)))
+	assert.NoError(t, err)
+	sync := storage.FinishSync()
+
+	events := storage.Events("goodreads/40445")
+	assert.Len(t, events, 2)
+	assert.Equal(t, "libra", events[0].Device)
+	assert.Equal(t, string(ImportGoodreads), events[1].Device)
+	assert.Equal(t, "2019-08-20T00:00:00.000", events[1].Time)
+
+	// Undoing the import restores the finish it replaced
+	_, err = storage.UndoSync(sync.ID)
+	assert.NoError(t, err)
+
+	events = storage.Events("goodreads/40445")
+	assert.Len(t, events, 2)
+	assert.Equal(t, "2019-08-14T00:00:00.000", events[1].Time)
+}
+
+func TestImportGoodreadsCSVErrors(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	_, err = ImportGoodreadsCSV(storage, strings.NewReader("Book Id,Title,Date Read\n1,Carrie,yesterday\n"))
+	assert.Error(t, err)
+
+	_, err = ParseImportSource("librarything")
+	assert.ErrorIs(t, err, ErrUnknownImportSource)
+}
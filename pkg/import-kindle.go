@@ -0,0 +1,252 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	kindleModel         = "Kindle import"
+	kindleContentPrefix = "kindle/"
+
+	// kindleSeparator is the line between clippings
+	kindleSeparator = "=========="
+
+	// Kobo bookmark types
+	bookmarkTypeHighlight = "highlight"
+	bookmarkTypeNote      = "note"
+	bookmarkTypeDogear    = "dogear"
+)
+
+var (
+	kindleTypeRe     = regexp.MustCompile(`(?i)^-\s*(?:your\s+)?(highlight|note|bookmark)\b`)
+	kindleLocationRe = regexp.MustCompile(`(?i)\b(?:location|loc\.)\s*(\d+)(?:-(\d+))?`)
+	kindlePageRe     = regexp.MustCompile(`(?i)\bpage\s+(\d+)`)
+	kindleAddedRe    = regexp.MustCompile(`(?i)added on\s+(.+)$`)
+
+	// kindleTimeLayouts are the "Added on" times of English Kindles
+	kindleTimeLayouts = []string{
+		"Monday, 2 January 2006 15:04:05",
+		"Monday, January 2, 2006 3:04:05 PM",
+		"Monday, January 2, 2006, 3:04 PM",
+		"Monday, 2 January 06 15:04:05",
+	}
+)
+
+// kindleClipping is one entry of My Clippings.txt
+type kindleClipping struct {
+	Title  string
+	Author string
+	Type   string
+
+	Page          int
+	LocationStart int
+	LocationEnd   int
+
+	Added time.Time
+	Text  string
+}
+
+// ImportKindleClippings imports the highlights, notes and bookmarks of a Kindle "My Clippings.txt" as bookmarks of
+// content "kindle/<author>/<title>". A note is the annotation of the highlight at its location. Entries that cannot be
+// parsed e.g. from a Kindle in another language are rejected. Importing the same file again does not duplicate anything.
+func ImportKindleClippings(storage Storage, r io.Reader) (ImportResult, error) {
+	clippingsBytes, err := io.ReadAll(r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	clippings, rejected := parseKindleClippings(string(clippingsBytes))
+
+	writer := newImportWriter(storage, ImportKindle, kindleModel, "")
+	writer.result.Rejected = rejected
+
+	existing := map[string]bool{}
+	for _, content := range storage.Contents() {
+		existing[content.ID] = true
+	}
+
+	for _, clipping := range clippings {
+		cid := kindleContentID(clipping)
+		if !existing[cid] {
+			writer.addBook(importBook{ID: cid, Title: clipping.Title, Author: clipping.Author})
+			existing[cid] = true
+		}
+
+		modified := clipping.Added
+		annotation := ""
+
+		if clipping.Type == bookmarkTypeNote {
+			annotation = clipping.Text
+			clipping.Text = ""
+		}
+
+		if clipping.Type == bookmarkTypeHighlight {
+			// A note within the highlight location is its annotation
+			if note := kindleNoteOf(clipping, clippings); note != nil {
+				clipping.Type = bookmarkTypeNote
+				annotation = note.Text
+				modified = note.Added
+			}
+		} else if clipping.Type == bookmarkTypeNote && kindleHighlightOf(clipping, clippings) != nil {
+			// Already the annotation of the highlight
+			continue
+		}
+
+		storage.AddBookmark(kindleBookmarkID(cid, clipping), cid, cid, clipping.Type, "", clipping.Page,
+			clipping.LocationStart, clipping.LocationEnd, clipping.Text, annotation, clipping.Added, modified)
+		writer.result.Bookmarks++
+	}
+
+	return writer.result, nil
+}
+
+// parseKindleClippings returns the clippings that could be parsed and the entries that could not
+func parseKindleClippings(s string) ([]kindleClipping, []ImportRejection) {
+	s = strings.TrimPrefix(strings.ReplaceAll(s, "\r\n", "\n"), "\ufeff")
+
+	result := make([]kindleClipping, 0)
+	rejected := make([]ImportRejection, 0)
+
+	for idx, entry := range strings.Split(s, kindleSeparator) {
+		lines := strings.Split(strings.Trim(entry, "\n\ufeff "), "\n")
+		if len(lines) < 2 {
+			if strings.TrimSpace(entry) != "" {
+				rejected = append(rejected, ImportRejection{Row: idx + 1, Reason: "incomplete clipping"})
+			}
+
+			continue
+		}
+
+		clipping, err := parseKindleClipping(lines)
+		if err != nil {
+			rejected = append(rejected, ImportRejection{Row: idx + 1, Reason: err.Error()})
+			continue
+		}
+
+		result = append(result, clipping)
+	}
+
+	return result, rejected
+}
+
+// parseKindleClipping parses the title (author) line, the meta line and the text of an entry
+func parseKindleClipping(lines []string) (kindleClipping, error) {
+	clipping := kindleClipping{}
+	clipping.Title, clipping.Author = kindleTitleAuthor(strings.TrimSpace(strings.TrimPrefix(lines[0], "\ufeff")))
+
+	meta := strings.TrimSpace(lines[1])
+
+	typeMatch := kindleTypeRe.FindStringSubmatch(meta)
+	if typeMatch == nil {
+		return clipping, fmt.Errorf("unknown clipping %q", meta)
+	}
+
+	switch strings.ToLower(typeMatch[1]) {
+	case "highlight":
+		clipping.Type = bookmarkTypeHighlight
+	case "note":
+		clipping.Type = bookmarkTypeNote
+	default:
+		clipping.Type = bookmarkTypeDogear
+	}
+
+	if pageMatch := kindlePageRe.FindStringSubmatch(meta); pageMatch != nil {
+		clipping.Page, _ = strconv.Atoi(pageMatch[1])
+	}
+
+	if locationMatch := kindleLocationRe.FindStringSubmatch(meta); locationMatch != nil {
+		clipping.LocationStart, _ = strconv.Atoi(locationMatch[1])
+		clipping.LocationEnd = clipping.LocationStart
+
+		if locationMatch[2] != "" {
+			clipping.LocationEnd, _ = strconv.Atoi(locationMatch[2])
+		}
+	}
+
+	addedMatch := kindleAddedRe.FindStringSubmatch(meta)
+	if addedMatch == nil {
+		return clipping, fmt.Errorf("no added time %q", meta)
+	}
+
+	added, err := parseKindleTime(addedMatch[1])
+	if err != nil {
+		return clipping, err
+	}
+
+	clipping.Added = added
+	clipping.Text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+
+	return clipping, nil
+}
+
+// kindleTitleAuthor splits "Altered Carbon (Richard K. Morgan)" into the title and author
+func kindleTitleAuthor(s string) (string, string) {
+	if !strings.HasSuffix(s, ")") {
+		return s, ""
+	}
+
+	idx := strings.LastIndex(s, "(")
+	if idx <= 0 {
+		return s, ""
+	}
+
+	return strings.TrimSpace(s[:idx]), strings.TrimSpace(s[idx+1 : len(s)-1])
+}
+
+// parseKindleTime parses the "Added on" time in local time
+func parseKindleTime(ts string) (time.Time, error) {
+	for _, layout := range kindleTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(ts), time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown time format %q", ts)
+}
+
+func kindleContentID(clipping kindleClipping) string {
+	return kindleContentPrefix + slugify(clipping.Author) + "/" + slugify(clipping.Title)
+}
+
+// kindleBookmarkID is a stable ID of the clipping so importing again does not duplicate it
+func kindleBookmarkID(cid string, clipping kindleClipping) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%s", cid, clipping.LocationStart, clipping.LocationEnd,
+		clipping.Added.Format(StorageTimeFmt))))
+
+	return "kindle-" + hex.EncodeToString(sum[:8])
+}
+
+// kindleNoteOf returns the first note of the same book within the highlight location
+func kindleNoteOf(highlight kindleClipping, clippings []kindleClipping) *kindleClipping {
+	for idx := range clippings {
+		if clippings[idx].Type == bookmarkTypeNote && kindleAt(highlight, clippings[idx]) {
+			return &clippings[idx]
+		}
+	}
+
+	return nil
+}
+
+// kindleHighlightOf returns the first highlight of the same book that the note is within
+func kindleHighlightOf(note kindleClipping, clippings []kindleClipping) *kindleClipping {
+	for idx := range clippings {
+		if clippings[idx].Type == bookmarkTypeHighlight && kindleAt(clippings[idx], note) {
+			return &clippings[idx]
+		}
+	}
+
+	return nil
+}
+
+// kindleAt is true when the note is in the same book and its location is within the highlight
+func kindleAt(highlight, note kindleClipping) bool {
+	return highlight.Title == note.Title && highlight.Author == note.Author && note.LocationStart != 0 &&
+		note.LocationStart >= highlight.LocationStart && note.LocationStart <= highlight.LocationEnd
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKindleClippings = "\ufeffAltered Carbon (Richard K. Morgan)\r\n" +
+	"- Your Highlight on page 12 | Location 180-182 | Added on Sunday, 14 May 2017 10:12:33\r\n" +
+	"\r\n" +
+	"Sleeving is a messy business.\r\n" +
+	"==========\r\n" +
+	"Altered Carbon (Richard K. Morgan)\r\n" +
+	"- Your Note on page 12 | Location 182 | Added on Sunday, 14 May 2017 10:13:05\r\n" +
+	"\r\n" +
+	"Great opening\r\n" +
+	"==========\r\n" +
+	"Matilda (Dahl, Roald)\r\n" +
+	"- Your Bookmark on Location 200 | Added on Monday, May 15, 2017 8:01:00 PM\r\n" +
+	"\r\n" +
+	"\r\n" +
+	"==========\r\n" +
+	"Matilda (Dahl, Roald)\r\n" +
+	"- Ihre Markierung bei Position 210-212 | Hinzugefügt am Montag, 15. Mai 2017 20:05:00\r\n" +
+	"\r\n" +
+	"Nicht unterstützt\r\n" +
+	"==========\r\n"
+
+func TestImportKindleClippings(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	result, err := ImportKindleClippings(storage, strings.NewReader(testKindleClippings))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Contents)
+	assert.Equal(t, 2, result.Bookmarks)
+	assert.Len(t, result.Rejected, 1)
+	assert.Equal(t, 4, result.Rejected[0].Row)
+
+	// Importing again does not duplicate anything
+	_, err = ImportKindleClippings(storage, strings.NewReader(testKindleClippings))
+	assert.NoError(t, err)
+
+	assert.Len(t, storage.Contents(), 2)
+
+	bookmarks := storage.Bookmarks("kindle/richard-k-morgan/altered-carbon")
+	assert.Len(t, bookmarks, 1)
+	assert.Equal(t, bookmarkTypeNote, bookmarks[0].Type)
+	assert.Equal(t, "Sleeving is a messy business.", bookmarks[0].Text)
+	assert.Equal(t, "Great opening", bookmarks[0].Annotation)
+	assert.Equal(t, 12, bookmarks[0].Index)
+	assert.Equal(t, 180, bookmarks[0].StartOffset)
+	assert.Equal(t, 182, bookmarks[0].EndOffset)
+	assert.Equal(t, "2017-05-14T10:12:33.000", bookmarks[0].Created)
+	assert.Equal(t, "2017-05-14T10:13:05.000", bookmarks[0].Modified)
+
+	content, err := FindContent(storage.Contents(), "Matilda")
+	assert.NoError(t, err)
+	assert.Equal(t, "Dahl, Roald", content.Author)
+
+	bookmarks = storage.Bookmarks(content.ID)
+	assert.Len(t, bookmarks, 1)
+	assert.Equal(t, bookmarkTypeDogear, bookmarks[0].Type)
+}
+
+func TestParseKindleTime(t *testing.T) {
+	expected := time.Date(2017, 5, 15, 20, 1, 0, 0, time.Local)
+
+	for _, ts := range []string{"Monday, 15 May 2017 20:01:00", "Monday, May 15, 2017 8:01:00 PM", "Monday, May 15, 2017, 8:01 PM"} {
+		actual, err := parseKindleTime(ts)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err := parseKindleTime("Montag, 15. Mai 2017 20:01:00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	storyGraphModel         = "StoryGraph import"
+	storyGraphContentPrefix = "storygraph/"
+	storyGraphShelfType     = "StoryGraph"
+
+	// storyGraphReadStatus is the read status of finished books
+	storyGraphReadStatus = "read"
+)
+
+// ImportStoryGraphCSV imports a StoryGraph export. Each row is book content "storygraph/<ISBN/UID>" with a Finish
+// event at the end of each of the Dates Read. Tags, moods (e.g. "mood:dark"), the format (e.g. "format:audio") and the
+// read status are added as shelves. Importing the same export again updates the content without duplicating events.
+func ImportStoryGraphCSV(storage Storage, r io.Reader) (ImportResult, error) {
+	records, err := csvRecords(r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	writer := newImportWriter(storage, ImportStoryGraph, storyGraphModel, storyGraphShelfType)
+
+	for idx, record := range records {
+		if record["Title"] == "" {
+			return writer.result, fmt.Errorf("row %d: missing Title", idx+2)
+		}
+
+		book := importBook{
+			ID:       storyGraphContentID(record),
+			Title:    record["Title"],
+			Author:   record["Authors"],
+			Finished: record["Read Status"] == storyGraphReadStatus,
+			Shelves:  splitList(record["Tags"]),
+		}
+
+		if isISBN(record["ISBN/UID"]) {
+			book.ISBN = record["ISBN/UID"]
+		}
+
+		if rating, err := strconv.ParseFloat(record["Star Rating"], 64); err == nil {
+			book.Rating = int(math.Round(rating))
+		}
+
+		book.ReadCount, _ = strconv.Atoi(record["Read Count"])
+
+		book.Finishes, err = storyGraphFinishes(record["Dates Read"], record["Last Date Read"])
+		if err != nil {
+			return writer.result, fmt.Errorf("row %d: %w", idx+2, err)
+		}
+
+		// Every date read is a read-through even when the read count is missing
+		book.ReadCount = max(book.ReadCount, len(book.Finishes))
+
+		for _, mood := range splitList(record["Moods"]) {
+			book.Shelves = append(book.Shelves, "mood:"+mood)
+		}
+
+		if format := record["Format"]; format != "" {
+			book.Shelves = append(book.Shelves, "format:"+format)
+		}
+
+		if status := record["Read Status"]; status != "" {
+			book.Shelves = append(book.Shelves, status)
+		}
+
+		writer.addBook(book)
+	}
+
+	return writer.result, nil
+}
+
+// storyGraphContentID is the ISBN/UID of the row, or the author and title when the export has neither
+func storyGraphContentID(record map[string]string) string {
+	if uid := record["ISBN/UID"]; uid != "" {
+		return storyGraphContentPrefix + uid
+	}
+
+	return storyGraphContentPrefix + slugify(record["Authors"]) + "/" + slugify(record["Title"])
+}
+
+// storyGraphFinishes returns the end of each of the dates read e.g. "2023/01/05-2023/01/20, 2024/02/01-2024/02/09",
+// or the last date read when there are no dates read
+func storyGraphFinishes(datesRead, lastDateRead string) ([]time.Time, error) {
+	result := make([]time.Time, 0)
+
+	for _, dates := range splitList(datesRead) {
+		parts := strings.Split(dates, "-")
+
+		finish, err := ParseManualTime(parts[len(parts)-1])
+		if err != nil {
+			return nil, fmt.Errorf("dates read: %w", err)
+		}
+
+		result = append(result, finish)
+	}
+
+	if len(result) == 0 && lastDateRead != "" {
+		finish, err := ParseManualTime(lastDateRead)
+		if err != nil {
+			return nil, fmt.Errorf("last date read: %w", err)
+		}
+
+		result = append(result, finish)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStoryGraphCSV = `Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Dates Read,Read Count,Moods,Pace,Character- or Plot-Driven?,Strong Character Development?,Loveable Characters?,Diverse Characters?,Flawed Characters?,Star Rating,Review,Content Warnings,Content Warning Description,Tags,Owned?
+Altered Carbon,Richard K. Morgan,,9780345457684,digital,read,2023/01/01,2024/02/09,"2021/03/01-2021/03/10, 2024/02/01-2024/02/09",2,"dark, mysterious",fast,Plot,Yes,No,No,Yes,4.5,,,,"sci-fi, favourites",No
+Matilda,Roald Dahl,Quentin Blake,,paperback,to-read,2023/01/01,,,0,,,,,,,,,,,,,No
+`
+
+func TestImportStoryGraphCSV(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	result, err := ImportStoryGraphCSV(storage, strings.NewReader(testStoryGraphCSV))
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{Contents: 2, Finishes: 2, Shelves: 8}, result)
+
+	// Importing again does not duplicate anything
+	_, err = ImportStoryGraphCSV(storage, strings.NewReader(testStoryGraphCSV))
+	assert.NoError(t, err)
+
+	contents := storage.Contents()
+	assert.Len(t, contents, 2)
+
+	content, err := FindContent(contents, "storygraph/9780345457684")
+	assert.NoError(t, err)
+	assert.Equal(t, "9780345457684", content.ISBN)
+	assert.Equal(t, 5, content.Rating)
+	assert.True(t, content.IsFinished)
+	assert.Len(t, storage.Events(content.ID), 2)
+
+	content, err = FindContent(contents, "Matilda")
+	assert.NoError(t, err)
+	assert.Equal(t, "storygraph/roald-dahl/matilda", content.ID)
+	assert.False(t, content.IsFinished)
+
+	assert.Len(t, storage.ShelfContents("mood:dark"), 1)
+	assert.Len(t, storage.ShelfContents("format:paperback"), 1)
+	assert.Len(t, storage.ShelfContents("to-read"), 1)
+
+	// Each date read is a finished read-through
+	stats := NewStats(storage)
+	assert.Len(t, stats.BooksFinishedYear(2021), 1)
+	assert.Len(t, stats.BooksFinishedYear(2024), 1)
+}
+
+func TestStoryGraphFinishes(t *testing.T) {
+	finishes, err := storyGraphFinishes("", "2024/02/09")
+	assert.NoError(t, err)
+	assert.Len(t, finishes, 1)
+
+	finishes, err = storyGraphFinishes("2024/02/09", "")
+	assert.NoError(t, err)
+	assert.Len(t, finishes, 1)
+
+	_, err = storyGraphFinishes("2024/02/01-soon", "")
+	assert.Error(t, err)
+}
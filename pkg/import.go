@@ -0,0 +1,273 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ImportSource is another reading tracker whose export can be imported
+type ImportSource string
+
+const (
+	ImportGoodreads  ImportSource = "goodreads"
+	ImportStoryGraph ImportSource = "storygraph"
+	ImportKindle     ImportSource = "kindle"
+	ImportCSV        ImportSource = "csv"
+)
+
+var ErrUnknownImportSource = errors.New("unknown import source")
+
+// ImportOptions are the options of the importers that need them e.g. the column mapping of a csv import
+type ImportOptions struct {
+	Mapping ImportMapping
+
+	// Format of the file csv or ndjson, default from the file extension
+	Format string
+}
+
+// ImportResult counts what an import added or updated
+type ImportResult struct {
+	Contents  int `json:"contents"`
+	Finishes  int `json:"finishes"`
+	Shelves   int `json:"shelves"`
+	Bookmarks int `json:"bookmarks"`
+
+	// Rejected are the rows or entries that could not be imported
+	Rejected []ImportRejection `json:"rejected,omitempty"`
+}
+
+// ImportRejection is a row or entry of the file that was not imported and why. Row is the line number of a csv or
+// ndjson file, or the number of the entry e.g. a Kindle clipping
+type ImportRejection struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// importer reads an export and adds it to storage
+type importer func(storage Storage, r io.Reader, options ImportOptions) (ImportResult, error)
+
+var importers = map[ImportSource]importer{
+	ImportGoodreads:  withoutOptions(ImportGoodreadsCSV),
+	ImportStoryGraph: withoutOptions(ImportStoryGraphCSV),
+	ImportKindle:     withoutOptions(ImportKindleClippings),
+	ImportCSV:        ImportReadingLog,
+}
+
+// withoutOptions is the importer of an export that has no options
+func withoutOptions(imp func(storage Storage, r io.Reader) (ImportResult, error)) importer {
+	return func(storage Storage, r io.Reader, _ ImportOptions) (ImportResult, error) {
+		return imp(storage, r)
+	}
+}
+
+// ImportSources returns the names of the supported import sources
+func ImportSources() []string {
+	result := make([]string, 0, len(importers))
+	for source := range importers {
+		result = append(result, string(source))
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// ParseImportSource returns the import source by name e.g. "goodreads"
+func ParseImportSource(name string) (ImportSource, error) {
+	source := ImportSource(strings.ToLower(name))
+	if _, exists := importers[source]; !exists {
+		return "", fmt.Errorf("%w: %s (use %s)", ErrUnknownImportSource, name, strings.Join(ImportSources(), ", "))
+	}
+
+	return source, nil
+}
+
+// ImportFile imports the export file of the source into storage. The import is recorded in the journal like a sync
+// with the source as the device so it is shown by history and can be undone
+func ImportFile(storage Storage, source ImportSource, fn string, options ImportOptions) (ImportResult, error) {
+	imp, exists := importers[source]
+	if !exists {
+		return ImportResult{}, fmt.Errorf("%w: %s", ErrUnknownImportSource, source)
+	}
+
+	hash, err := hashFile(fn)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	fp, err := os.Open(fn)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	if options.Format == "" {
+		options.Format = importFormat(fn)
+	}
+
+	storage.StartSync(string(source), fn, hash, time.Now())
+
+	result, err := imp(storage, fp, options)
+
+	storage.FinishSync()
+
+	return result, err
+}
+
+// importFormat is the format of the file from its extension, ndjson for .ndjson or .jsonl otherwise csv
+func importFormat(fn string) string {
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".ndjson", ".jsonl":
+		return importFormatNDJSON
+	}
+
+	return importFormatCSV
+}
+
+// importBook is one book of an export
+type importBook struct {
+	ID     string
+	Title  string
+	Author string
+	ISBN   string
+	Words  int
+
+	Rating    int
+	ReadCount int
+
+	Finished bool
+	Finishes []time.Time
+
+	Shelves []string
+}
+
+// importWriter adds the books of an export to storage through the same paths as sync
+type importWriter struct {
+	storage   Storage
+	device    string
+	shelfType string
+
+	shelves map[string]bool
+	result  ImportResult
+}
+
+// newImportWriter adds the pseudo-device of the source e.g. "goodreads" and returns a writer for its books
+func newImportWriter(storage Storage, source ImportSource, model, shelfType string) *importWriter {
+	storage.AddDevice(string(source), model)
+
+	return &importWriter{
+		storage:   storage,
+		device:    string(source),
+		shelfType: shelfType,
+		shelves:   map[string]bool{},
+	}
+}
+
+// addBook adds the content, a Finish event per finish and the book to its shelves. Finish events of an earlier import
+// that are not finishes of the book any more e.g. a changed Date Read are removed
+func (w *importWriter) addBook(book importBook) {
+	w.storage.AddContent(book.ID, book.Title, book.Author, "", book.ISBN, book.Words, true, book.Finished || len(book.Finishes) > 0, 0)
+	w.storage.SetContentDetails(book.ID, book.Rating, book.ReadCount)
+	w.result.Contents++
+
+	finishes := map[string]bool{}
+	for _, finish := range book.Finishes {
+		finishes[finish.Format(StorageTimeFmt)] = true
+	}
+
+	for _, event := range w.storage.Events(book.ID) {
+		if event.Device != w.device || event.EventName != FinishEvent.String() || finishes[event.Time] {
+			continue
+		}
+
+		if t, err := time.Parse(StorageTimeFmt, event.Time); err == nil {
+			w.storage.RemoveEvent(book.ID, w.device, event.EventName, t)
+		}
+	}
+
+	for _, finish := range book.Finishes {
+		w.storage.AddEvent(book.ID, w.device, FinishEvent.String(), finish, 0)
+		w.result.Finishes++
+	}
+
+	for _, shelfName := range book.Shelves {
+		if !w.shelves[shelfName] {
+			w.storage.AddShelf(w.device+"/"+shelfName, shelfName, shelfName, w.shelfType, false)
+			w.shelves[shelfName] = true
+		}
+
+		w.storage.AddShelfContent(shelfName, book.ID, false)
+	}
+
+	w.result.Shelves = len(w.shelves)
+}
+
+// csvRecords reads a csv with a header row and returns each row as a map of column name to value
+func csvRecords(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	header := rows[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // byte order mark
+	}
+
+	result := make([]map[string]string, 0, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		record := make(map[string]string, len(header))
+		for idx := range header {
+			if idx < len(row) {
+				record[strings.TrimSpace(header[idx])] = strings.TrimSpace(row[idx])
+			}
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// splitList splits a comma separated list e.g. "to-read, favourites" ignoring empty items
+func splitList(s string) []string {
+	result := make([]string, 0)
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// isISBN is true when s is only an ISBN-10 or ISBN-13 e.g. "978-0-345-45768-4" and not another ID
+func isISBN(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != 'X' && r != 'x' && r != '-' {
+			return false
+		}
+	}
+
+	return normaliseISBN(s) != ""
+}
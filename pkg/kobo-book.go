@@ -1,14 +1,20 @@
 package pkg
 
+import "time"
+
 type KoboBook struct {
 	ID     string
 	Title  string
 	Author string
 	URL    string
+	ISBN   string
 
 	Finished        bool
 	ProgressPercent int
 
+	// LastFinished is the content LastTimeFinishedReading, zero if never finished
+	LastFinished time.Time
+
 	Parts map[string]KoboBookPart
 
 	// IsBook true for book, false for Pocket
@@ -19,6 +19,7 @@ import (
 
 type KoboDatabase interface {
 	Device() (string, string)
+	Source() (string, string)
 
 	Contents() ([]KoboBook, error)
 	Events() ([]KoboEvent, error)
@@ -35,6 +36,9 @@ type koboDatabase struct {
 	fn   string
 	conn *sqlite3.Conn
 
+	// hash is the sha256 of the database file when it was opened
+	hash string
+
 	// device contains the first value from the .kobo/version file (model + serial)
 	device string
 	model  string
@@ -61,12 +65,17 @@ const (
 
 func NewKoboDatabase(fn string) (KoboDatabase, error) {
 	// todo read-only ! conn, err := sqlite3.OpenFlags(fn, sqlite3.OPEN_READONLY)
-	conn, err := sqlite3.Open(fn)
+	device, err := getDevice(fn)
 	if err != nil {
 		return nil, err
 	}
 
-	device, err := getDevice(fn)
+	hash, err := hashFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sqlite3.Open(fn)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +83,7 @@ func NewKoboDatabase(fn string) (KoboDatabase, error) {
 	return koboDatabase{
 		fn:     fn,
 		conn:   conn,
+		hash:   hash,
 		device: device,
 		model:  getModel(device),
 	}, nil
@@ -83,8 +93,13 @@ func (k koboDatabase) Device() (string, string) {
 	return k.device, k.model
 }
 
+// Source returns the database filename and sha256 hash
+func (k koboDatabase) Source() (string, string) {
+	return k.fn, k.hash
+}
+
 func (k koboDatabase) Contents() ([]KoboBook, error) {
-	stmt, _, err := k.conn.Prepare(`SELECT ContentID, BookID, ContentType, MimeType, Title, BookTitle, Attribution, ReadStatus, WordCount, ___PercentRead, ContentURL FROM content`)
+	stmt, _, err := k.conn.Prepare(`SELECT ContentID, BookID, ContentType, MimeType, Title, BookTitle, Attribution, ReadStatus, WordCount, ___PercentRead, ContentURL, ISBN, LastTimeFinishedReading FROM content`)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +120,8 @@ func (k koboDatabase) Contents() ([]KoboBook, error) {
 		wordCount := stmt.ColumnInt(8)
 		pcRead := stmt.ColumnInt(9)
 		contentURL := stmt.ColumnText(10)
+		isbn := stmt.ColumnText(11)
+		lastFinished := k.parseTimeOrZero(stmt.ColumnText(12))
 
 		/*fmt.Printf("koboDatabase.Contents! cID=%s bID=%s contentType=%s mimeType=%s title=%s bookTitle=%s author=%s readStatus=%d wordCount=%d pcRead=%d contentURL=%s\n",
 		cID, bID, contentType, mimeType, title, bookTitle, author, readStatus, wordCount, pcRead, contentURL)*/
@@ -143,6 +160,8 @@ func (k koboDatabase) Contents() ([]KoboBook, error) {
 				result[index[fn]].Title = title
 				result[index[fn]].Author = author
 				result[index[fn]].URL = contentURL
+				result[index[fn]].ISBN = isbn
+				result[index[fn]].LastFinished = lastFinished
 				result[index[fn]].ProgressPercent = pcRead
 			} else {
 				if wordCount > 0 {
@@ -517,7 +536,11 @@ func (k koboDatabase) Bookmarks() ([]KoboBookmark, error) {
 func (k koboDatabase) parseTimeOrZero(ts string) time.Time {
 	t, err := time.Parse(KoboTimeFmt, ts)
 	if err != nil {
-		return time.Time{}
+		// Some columns e.g. LastTimeFinishedReading use RFC3339 "2023-12-19T11:42:00Z"
+		t, err = time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return time.Time{}
+		}
 	}
 
 	return t
@@ -39,4 +39,7 @@ const (
 	Progress50Event KoboEventType = "50%"
 	Progress75Event KoboEventType = "75%"
 	FinishEvent     KoboEventType = "Finish"
+
+	// LastFinishedEvent is the LastTimeFinishedReading from the content table
+	LastFinishedEvent KoboEventType = "LastFinished"
 )
@@ -1,6 +1,9 @@
 package pkg
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,6 +25,25 @@ func getDevice(fn string) (string, error) {
 	return stringData[:firstComma], nil
 }
 
+// hashFile returns the hex sha256 of the file contents
+func hashFile(fn string) (string, error) {
+	fp, err := os.Open(fn)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, fp); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // getModel simple lookup for device to human readable model
 func getModel(device string) string {
 	// models from https://help.kobo.com/hc/en-us/articles/360019676973-Identify-your-Kobo-eReader-or-Kobo-tablet
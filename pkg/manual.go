@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// ManualDevice is the pseudo-device of manual reading entries e.g. paper books and audiobooks
+	ManualDevice = "manual"
+	manualModel  = "Manual entry"
+
+	manualContentPrefix = "manual/"
+
+	// wordsPerPage to estimate words from a page count
+	wordsPerPage = 275
+)
+
+var (
+	ErrManualNoTitle  = errors.New("title is required")
+	ErrManualDuration = errors.New("session duration must be positive")
+)
+
+// ManualEntry is reading that was not on a Kobo
+type ManualEntry struct {
+	Title  string
+	Author string
+
+	// Words or Pages (estimated to words) are optional
+	Words int
+	Pages int
+
+	Sessions []ManualSession
+
+	// Finished is the finish time, zero if not finished
+	Finished time.Time
+
+	// Device is the pseudo-device for the entry, default ManualDevice
+	Device string
+}
+
+type ManualSession struct {
+	Start    time.Time
+	Duration time.Duration
+}
+
+// ManualContentID returns the content ID for a manual entry e.g. "manual/stephen-king/the-green-mile"
+func ManualContentID(title, author string) string {
+	return manualContentPrefix + slugify(author) + "/" + slugify(title)
+}
+
+// AddManualEntry adds the content and sessions of a manual entry to storage and returns the content ID. Existing
+// content with the same title and author is updated and sessions are deduplicated like synced sessions.
+func AddManualEntry(storage Storage, entry ManualEntry) (string, error) {
+	if strings.TrimSpace(entry.Title) == "" {
+		return "", ErrManualNoTitle
+	}
+
+	for _, session := range entry.Sessions {
+		if session.Duration <= 0 {
+			return "", fmt.Errorf("%w: %s", ErrManualDuration, session.Duration)
+		}
+	}
+
+	device := entry.Device
+	if device == "" {
+		device = ManualDevice
+	}
+
+	cid := ManualContentID(entry.Title, entry.Author)
+
+	words := entry.Words
+	if words == 0 {
+		words = entry.Pages * wordsPerPage
+	}
+
+	previous := StorageContent{}
+	for _, content := range storage.Contents() {
+		if content.ID == cid {
+			previous = content
+			break
+		}
+	}
+
+	if words == 0 {
+		words = previous.Words
+	}
+
+	storage.AddDevice(device, manualModel)
+	storage.AddContent(cid, entry.Title, entry.Author, "", "", words, true, !entry.Finished.IsZero(), 0)
+
+	for _, session := range entry.Sessions {
+		storage.AddEvent(cid, device, ReadEvent.String(), session.Start, int(session.Duration.Seconds()))
+	}
+
+	if !entry.Finished.IsZero() {
+		storage.AddEvent(cid, device, FinishEvent.String(), entry.Finished, 0)
+	}
+
+	return cid, nil
+}
+
+// ParseManualTime parses a local date or time e.g. "2024-01-02", "2024-01-02 20:15" or "2024-01-02T20:15:00"
+func ParseManualTime(ts string) (time.Time, error) {
+	layouts := []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", StorageTimeFmt, "2006-01-02", "2006/01/02"}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(ts), time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown time format %q (use 2006-01-02 or 2006-01-02 15:04)", ts)
+}
+
+// ParseManualTimeEnd parses the end of a time range, a date without a time is the end of the day e.g. "2024-12-31"
+// is 2025-01-01 00:00 so the range includes the whole day
+func ParseManualTimeEnd(ts string) (time.Time, error) {
+	t, err := ParseManualTime(ts)
+	if err != nil {
+		return t, err
+	}
+
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
+
+// slugify lower cases and replaces anything not a letter or digit with - e.g. "Green Mile, The" is "green-mile-the"
+func slugify(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
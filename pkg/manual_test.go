@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddManualEntry(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "manual.json"))
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	finished := time.Date(2024, 3, 2, 21, 0, 0, 0, time.UTC)
+
+	cid, err := AddManualEntry(storage, ManualEntry{
+		Title:    "The Green Mile",
+		Author:   "Stephen King",
+		Pages:    10,
+		Sessions: []ManualSession{{Start: start, Duration: 45 * time.Minute}},
+		Finished: finished,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "manual/stephen-king/the-green-mile", cid)
+
+	// Logging the same session again with no words keeps the words and does not duplicate the session
+	_, err = AddManualEntry(storage, ManualEntry{
+		Title:    "The Green Mile",
+		Author:   "Stephen King",
+		Sessions: []ManualSession{{Start: start, Duration: 45 * time.Minute}},
+		Finished: finished,
+	})
+	assert.NoError(t, err)
+
+	contents := storage.Contents()
+	assert.Len(t, contents, 1)
+	assert.Equal(t, 10*wordsPerPage, contents[0].Words)
+	assert.True(t, contents[0].IsBook)
+	assert.True(t, contents[0].IsFinished)
+
+	events := storage.Events(cid)
+	assert.Len(t, events, 2)
+
+	devices := storage.Devices()
+	assert.Len(t, devices, 1)
+	assert.Equal(t, ManualDevice, devices[0].Device)
+}
+
+func TestAddManualEntryErrors(t *testing.T) {
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "manual.json"))
+	assert.NoError(t, err)
+
+	_, err = AddManualEntry(storage, ManualEntry{Title: " "})
+	assert.ErrorIs(t, err, ErrManualNoTitle)
+
+	_, err = AddManualEntry(storage, ManualEntry{Title: "Carrie", Sessions: []ManualSession{{Start: time.Now()}}})
+	assert.ErrorIs(t, err, ErrManualDuration)
+}
+
+func TestParseManualTime(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected time.Time
+		wantErr  bool
+	}{
+		{in: "2024-01-02", expected: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)},
+		{in: "2024-01-02 20:15", expected: time.Date(2024, 1, 2, 20, 15, 0, 0, time.Local)},
+		{in: "2024-01-02T20:15:30", expected: time.Date(2024, 1, 2, 20, 15, 30, 0, time.Local)},
+		{in: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			actual, err := ParseManualTime(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"time"
+)
+
+// MergeConflict is content where the title or author differs between the storage files. The kept value is from the
+// destination storage
+type MergeConflict struct {
+	ContentID string `json:"content_id"`
+	Field     string `json:"field"`
+	Kept      string `json:"kept"`
+	Other     string `json:"other"`
+}
+
+// MergeStorage adds everything from src to dst using the same deduplication rules as sync. Events are unique by name
+// and time, bookmarks by ID and modified time and content cannot go from finished to unfinished. Conflicting titles
+// and authors are not overwritten but returned. Corrections are added for content without corrections in dst. The sync
+// journal is not merged.
+func MergeStorage(dst, src Storage) ([]MergeConflict, error) {
+	conflicts := make([]MergeConflict, 0)
+
+	for _, device := range src.Devices() {
+		dst.AddDevice(device.Device, device.Model)
+	}
+
+	existing := map[string]StorageContent{}
+	for _, content := range dst.Contents() {
+		existing[content.ID] = content
+	}
+
+	for _, content := range src.Contents() {
+		merged := content
+
+		if previous, exists := existing[content.ID]; exists {
+			merged = previous
+			merged.IsFinished = previous.IsFinished || content.IsFinished
+
+			if merged.Title == "" {
+				merged.Title = content.Title
+			} else if content.Title != "" && content.Title != previous.Title {
+				conflicts = append(conflicts, MergeConflict{ContentID: content.ID, Field: "title", Kept: previous.Title, Other: content.Title})
+			}
+
+			if merged.Author == "" {
+				merged.Author = content.Author
+			} else if content.Author != "" && content.Author != previous.Author {
+				conflicts = append(conflicts, MergeConflict{ContentID: content.ID, Field: "author", Kept: previous.Author, Other: content.Author})
+			}
+
+			if merged.URL == "" {
+				merged.URL = content.URL
+			}
+
+			if merged.ISBN == "" {
+				merged.ISBN = content.ISBN
+			}
+
+			if merged.Words == 0 {
+				merged.Words = content.Words
+			}
+
+			if merged.Rating == 0 {
+				merged.Rating = content.Rating
+			}
+
+			if merged.ReadCount == 0 {
+				merged.ReadCount = content.ReadCount
+			}
+		}
+
+		dst.AddContent(merged.ID, merged.Title, merged.Author, merged.URL, merged.ISBN, merged.Words, merged.IsBook, merged.IsFinished, 0)
+		dst.SetContentDetails(merged.ID, merged.Rating, merged.ReadCount)
+
+		for _, event := range src.Events(content.ID) {
+			eventTime, err := time.Parse(StorageTimeFmt, event.Time)
+			if err != nil {
+				return nil, err
+			}
+
+			dst.AddEvent(content.ID, event.Device, event.EventName, eventTime, event.Duration)
+		}
+
+		for _, bookmark := range src.Bookmarks(content.ID) {
+			created, err := parseStorageTimeOrZero(bookmark.Created)
+			if err != nil {
+				return nil, err
+			}
+
+			modified, err := parseStorageTimeOrZero(bookmark.Modified)
+			if err != nil {
+				return nil, err
+			}
+
+			dst.AddBookmark(bookmark.ID, bookmark.VolumeID, bookmark.ContentID, bookmark.Type, bookmark.BookPath,
+				bookmark.Index, bookmark.StartOffset, bookmark.EndOffset, bookmark.Text, bookmark.Annotation, created, modified)
+		}
+	}
+
+	for _, shelf := range src.Shelfs() {
+		dst.AddShelf(shelf.ID, shelf.Name, shelf.InternalName, shelf.Type, shelf.IsDeleted)
+
+		for _, shelfContent := range src.ShelfContents(shelf.Name) {
+			dst.AddShelfContent(shelf.Name, shelfContent.ContentID, shelfContent.IsDeleted)
+		}
+	}
+
+	dstCorrections := dst.Corrections()
+	for cID, correction := range src.Corrections() {
+		if _, exists := dstCorrections[cID]; !exists {
+			dst.SetCorrection(cID, correction)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// parseStorageTimeOrZero parses a StorageTimeFmt timestamp, empty is the zero time
+func parseStorageTimeOrZero(ts string) (time.Time, error) {
+	if ts == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(StorageTimeFmt, ts)
+}
@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeStorage(t *testing.T) {
+	const (
+		testDeviceA = "test-device-a"
+		testDeviceB = "test-device-b"
+		testBookID  = "books/test-book-a.epub"
+	)
+
+	dst, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "a.json"))
+	assert.NoError(t, err)
+
+	src, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "b.json"))
+	assert.NoError(t, err)
+
+	readTime := time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC)
+
+	dst.AddDevice(testDeviceA, "Kobo Libra 2")
+	dst.AddContent(testBookID, "Altered Carbon", "Richard K. Morgan", "", "", 100, true, false, 50)
+	dst.AddEvent(testBookID, testDeviceA, ReadEvent.String(), readTime, 600)
+
+	src.AddDevice(testDeviceB, "Kobo Clara 2E")
+	src.AddContent(testBookID, "Altered Carbon", "Richard K. K. Morgan", "", "", 100, true, true, 100)
+	src.AddEvent(testBookID, testDeviceA, ReadEvent.String(), readTime, 600)
+	src.AddEvent(testBookID, testDeviceB, ReadEvent.String(), readTime.Add(time.Hour), 300)
+	src.AddBookmark("bm", "vol", testBookID, "highlight", "", 0, 0, 0, "text", "", readTime, readTime)
+	src.AddShelf("Shelf", "Shelf", "Shelf", "UserTag", false)
+	src.AddShelfContent("Shelf", testBookID, false)
+
+	conflicts, err := MergeStorage(dst, src)
+	assert.NoError(t, err)
+
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "author", conflicts[0].Field)
+	assert.Equal(t, "Richard K. Morgan", conflicts[0].Kept)
+
+	contents := dst.Contents()
+	assert.Len(t, contents, 1)
+	assert.Equal(t, "Richard K. Morgan", contents[0].Author)
+	assert.True(t, contents[0].IsFinished)
+
+	assert.Len(t, dst.Devices(), 2)
+	assert.Len(t, dst.Events(testBookID), 2)
+	assert.Len(t, dst.Bookmarks(testBookID), 1)
+	assert.Len(t, dst.Shelfs(), 1)
+	assert.Len(t, dst.ShelfContents("Shelf"), 1)
+}
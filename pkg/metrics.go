@@ -0,0 +1,185 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	metricsPrefix = "kobo_readstat_"
+
+	// MetricsContentType is the content type of the Prometheus text exposition format
+	MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	metricsUnknownDevice = "unknown"
+)
+
+// metricsKey is the type (book or article) and device of the reading time and sessions
+type metricsKey struct {
+	Type   string
+	Device string
+}
+
+// WriteMetrics writes the stats in the Prometheus text exposition format. The days since the last session and the
+// current streak are of the day of now. Reads without a device e.g. imported have the device "unknown"
+func WriteMetrics(w io.Writer, stats Stats, devices []StorageDevice, now time.Time) error {
+	seconds := map[metricsKey]int{}
+	sessions := map[metricsKey]int{}
+	inProgress := map[string]int{"book": 0, "article": 0}
+	readDays := map[string]bool{}
+	lastDay := ""
+
+	for _, book := range stats.Content {
+		contentType := metricsType(book.IsBook)
+
+		for _, read := range book.Reads {
+			key := metricsKey{Type: contentType, Device: read.Device}
+			if key.Device == "" {
+				key.Device = metricsUnknownDevice
+			}
+
+			seconds[key] += read.Duration
+			sessions[key]++
+
+			day := markdownDate(read.Time)
+			readDays[day] = true
+			lastDay = max(lastDay, day)
+		}
+
+		if len(book.ReadThroughs) > 0 && !book.ReadThroughs[len(book.ReadThroughs)-1].IsFinished {
+			inProgress[contentType]++
+		}
+	}
+
+	keys := make([]metricsKey, 0, len(seconds))
+	for key := range seconds {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+
+		return keys[i].Device < keys[j].Device
+	})
+
+	m := &metricsWriter{w: w}
+
+	m.help("reading_seconds_total", "counter", "Total seconds of reading sessions by type (book or article) and device")
+	for _, key := range keys {
+		m.sample("reading_seconds_total", seconds[key], "type", key.Type, "device", key.Device)
+	}
+
+	m.help("sessions_total", "counter", "Total reading sessions by type (book or article) and device")
+	for _, key := range keys {
+		m.sample("sessions_total", sessions[key], "type", key.Type, "device", key.Device)
+	}
+
+	m.help("finished", "gauge", "Books and articles finished by type and year")
+	for _, year := range statsYears(stats) {
+		m.sample("finished", len(stats.BooksFinishedYear(year)), "type", "book", "year", fmt.Sprint(year))
+		m.sample("finished", len(stats.ArticlesFinishedYear(year)), "type", "article", "year", fmt.Sprint(year))
+	}
+
+	m.help("in_progress", "gauge", "Books and articles started and not finished by type")
+	m.sample("in_progress", inProgress["article"], "type", "article")
+	m.sample("in_progress", inProgress["book"], "type", "book")
+
+	today := now.Format(reportDateFmt)
+
+	if lastDay != "" {
+		m.help("days_since_last_session", "gauge", "Days since the last reading session, 0 when read today")
+		m.sample("days_since_last_session", metricsDaysBetween(lastDay, today))
+	}
+
+	m.help("streak_days", "gauge", "Consecutive days with a reading session until today, or yesterday when not read yet today")
+	m.sample("streak_days", metricsStreak(readDays, now))
+
+	sortedDevices := make([]StorageDevice, len(devices))
+	copy(sortedDevices, devices)
+	sort.Slice(sortedDevices, func(i, j int) bool {
+		return sortedDevices[i].Device < sortedDevices[j].Device
+	})
+
+	m.help("device_info", "gauge", "The model of each device, always 1")
+	for _, device := range sortedDevices {
+		m.sample("device_info", 1, "device", device.Device, "model", device.Model)
+	}
+
+	return m.err
+}
+
+func metricsType(isBook bool) string {
+	if isBook {
+		return "book"
+	}
+
+	return "article"
+}
+
+// metricsDaysBetween returns the days from the date to the later date
+func metricsDaysBetween(from, to string) int {
+	fromTime, _ := time.Parse(reportDateFmt, from)
+	toTime, _ := time.Parse(reportDateFmt, to)
+
+	return max(0, int(toTime.Sub(fromTime).Hours()/24))
+}
+
+// metricsStreak returns the consecutive days with a read until today, or until yesterday so the streak is not lost
+// before reading today
+func metricsStreak(readDays map[string]bool, now time.Time) int {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !readDays[day.Format(reportDateFmt)] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	result := 0
+	for readDays[day.Format(reportDateFmt)] {
+		result++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return result
+}
+
+type metricsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *metricsWriter) printf(format string, args ...any) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+func (m *metricsWriter) help(name, metricType, help string) {
+	m.printf("# HELP %s%s %s\n", metricsPrefix, name, help)
+	m.printf("# TYPE %s%s %s\n", metricsPrefix, name, metricType)
+}
+
+// sample writes a sample of the metric with the label names and values
+func (m *metricsWriter) sample(name string, value int, labels ...string) {
+	pairs := make([]string, 0, len(labels)/2)
+	for idx := 0; idx+1 < len(labels); idx += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[idx], metricsEscape(labels[idx+1])))
+	}
+
+	if len(pairs) == 0 {
+		m.printf("%s%s %d\n", metricsPrefix, name, value)
+		return
+	}
+
+	m.printf("%s%s{%s} %d\n", metricsPrefix, name, strings.Join(pairs, ","), value)
+}
+
+var metricsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsEscape escapes a label value
+func metricsEscape(value string) string {
+	return metricsEscaper.Replace(value)
+}
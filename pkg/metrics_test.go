@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "/mnt/onboard/books/matilda.epub"
+		testArticleID = "https://example.com/article"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddDevice(testDeviceAID, "Libra \"2\"")
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+
+	storage.AddContent(testBookBID, "Matilda", "Roald Dahl", "", "", 0, true, false, 10)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 3, 3, 8, 0, 0, 0, time.UTC), 300)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC), 300)
+	storage.AddEvent(testBookBID, "", ReadEvent.String(), time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC), 120)
+
+	storage.AddContent(testArticleID, "An article", "", "", "", 0, false, true, 100)
+	storage.AddEvent(testArticleID, testDeviceAID, ReadEvent.String(), time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC), 60)
+
+	t.Run("read today", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, WriteMetrics(&buf, NewStats(storage), storage.Devices(), time.Date(2024, 3, 5, 22, 0, 0, 0, time.Local)))
+
+		actual := buf.String()
+		assert.Contains(t, actual, "# TYPE kobo_readstat_reading_seconds_total counter\n")
+		assert.Contains(t, actual, `kobo_readstat_reading_seconds_total{type="article",device="test-device-a"} 60`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_reading_seconds_total{type="book",device="test-device-a"} 1200`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_reading_seconds_total{type="book",device="unknown"} 120`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_sessions_total{type="book",device="test-device-a"} 3`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_finished{type="book",year="2024"} 1`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_finished{type="article",year="2023"} 1`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_in_progress{type="book"} 1`+"\n")
+		assert.Contains(t, actual, `kobo_readstat_in_progress{type="article"} 0`+"\n")
+		assert.Contains(t, actual, "kobo_readstat_days_since_last_session 0\n")
+		assert.Contains(t, actual, "kobo_readstat_streak_days 3\n")
+		assert.Contains(t, actual, `kobo_readstat_device_info{device="test-device-a",model="Libra \"2\""} 1`+"\n")
+	})
+
+	t.Run("not read yet today", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, WriteMetrics(&buf, NewStats(storage), nil, time.Date(2024, 3, 6, 9, 0, 0, 0, time.Local)))
+
+		assert.Contains(t, buf.String(), "kobo_readstat_days_since_last_session 1\n")
+		assert.Contains(t, buf.String(), "kobo_readstat_streak_days 3\n")
+	})
+
+	t.Run("streak lost", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, WriteMetrics(&buf, NewStats(storage), nil, time.Date(2024, 3, 10, 9, 0, 0, 0, time.Local)))
+
+		assert.Contains(t, buf.String(), "kobo_readstat_days_since_last_session 5\n")
+		assert.Contains(t, buf.String(), "kobo_readstat_streak_days 0\n")
+	})
+}
@@ -0,0 +1,211 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MarkdownOptions are what the markdown report shows besides the books
+type MarkdownOptions struct {
+	// Highlights adds the highlights and notes made in the month as block quotes
+	Highlights bool
+
+	// Articles adds the article counts and time
+	Articles bool
+}
+
+var reportMonths = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+// WriteMarkdownReport writes the report as GitHub flavoured markdown: a summary table and a table per month of the
+// books finished, started and continued in the month
+func WriteMarkdownReport(w io.Writer, report Report, options MarkdownOptions) error {
+	md := &markdownWriter{w: w}
+
+	md.printf("# Reading Stats for %d\n\n", report.Year)
+
+	md.printf("| | |\n|---|---:|\n")
+	md.printf("| Finished books | %d |\n", report.Totals.BooksFinished)
+	md.printf("| Books read | %d |\n", report.Totals.BooksRead)
+	md.printf("| Time reading books | %s |\n", markdownHours(report.Totals.BookSeconds))
+
+	if options.Articles {
+		md.printf("| Finished articles | %d |\n", report.Totals.ArticlesFinished)
+		md.printf("| Time reading articles | %s |\n", markdownHours(report.Totals.ArticleSeconds))
+		md.printf("| Total time reading | %s |\n", markdownHours(report.Totals.TotalSeconds))
+	}
+
+	md.printf("| Reading sessions | %d |\n", report.Totals.Sessions)
+	md.printf("| Weekly average | %s |\n", markdownHours(report.Totals.WeeklyAverageSeconds))
+
+	for _, month := range report.Months {
+		if month.TotalSeconds == 0 && month.BooksFinished == 0 && month.ArticlesFinished == 0 {
+			continue
+		}
+
+		md.printf("\n## %s %d\n\n", reportMonths[month.Month], report.Year)
+
+		md.printf("Finished books: %d, time reading books: %s", month.BooksFinished, markdownHours(month.BookSeconds))
+		if options.Articles {
+			md.printf(". Finished articles: %d, time reading articles: %s", month.ArticlesFinished, markdownHours(month.ArticleSeconds))
+		}
+
+		md.printf("\n")
+
+		rows := markdownMonthRows(report.Books, report.Year, month.Month)
+		if len(rows) == 0 {
+			continue
+		}
+
+		md.printf("\n| Book | Author | Status | Duration | Sessions | Started | Finished |\n")
+		md.printf("|---|---|---|---:|---:|---|---|\n")
+
+		for _, row := range rows {
+			md.printf("| %s | %s | %s | %s | %d | %s | %s |\n", markdownCell(row.title), markdownCell(row.book.Author),
+				row.status, HumanizeDurationShort(time.Duration(row.seconds)*time.Second), row.sessions,
+				markdownDate(row.book.Started), row.finished)
+		}
+
+		if options.Highlights {
+			md.highlights(rows, fmt.Sprintf("%04d-%02d", report.Year, month.Month))
+		}
+	}
+
+	return md.err
+}
+
+// markdownRow is a book in a month table
+type markdownRow struct {
+	book     ReportBook
+	title    string
+	status   string
+	seconds  int
+	sessions int
+	finished string
+}
+
+// markdownMonthRows returns the books finished in the month, then those read in the month. Duration and sessions of a
+// finished book are the whole read-through, of the other books only the month
+func markdownMonthRows(books []ReportBook, year, month int) []markdownRow {
+	prefix := fmt.Sprintf("%04d-%02d", year, month)
+
+	result := make([]markdownRow, 0)
+
+	for _, book := range books {
+		row := markdownRow{book: book, title: book.Title}
+
+		if book.ReadThrough > 1 {
+			row.title = fmt.Sprintf("%s (read %d)", book.Title, book.ReadThrough)
+		}
+
+		if book.Status == ReportStatusFinished && strings.HasPrefix(book.Finished, prefix) {
+			row.status = "finished"
+			row.seconds = book.ReadSeconds
+			row.sessions = len(book.Sessions)
+
+			row.finished = markdownDate(book.Finished)
+			if book.Estimated {
+				row.finished += " (estimated)"
+			}
+
+			result = append(result, row)
+
+			continue
+		}
+
+		for _, session := range book.Sessions {
+			if strings.HasPrefix(session.Time, prefix) {
+				row.seconds += session.Duration
+				row.sessions++
+			}
+		}
+
+		if row.sessions == 0 {
+			continue
+		}
+
+		row.status = "continued"
+		if strings.HasPrefix(book.Started, prefix) {
+			row.status = "started"
+		}
+
+		result = append(result, row)
+	}
+
+	return result
+}
+
+type markdownWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *markdownWriter) printf(format string, args ...any) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+// highlights writes the highlights and notes created in the month of each book as block quotes
+func (m *markdownWriter) highlights(rows []markdownRow, prefix string) {
+	for _, row := range rows {
+		bookmarks := make([]StatsBookmark, 0)
+		for _, bookmark := range row.book.Bookmarks {
+			if strings.HasPrefix(bookmark.Created, prefix) && (bookmark.Text != "" || bookmark.Annotation != "") {
+				bookmarks = append(bookmarks, bookmark)
+			}
+		}
+
+		if len(bookmarks) == 0 {
+			continue
+		}
+
+		m.printf("\n### %s\n", markdownCell(row.title))
+
+		for _, bookmark := range bookmarks {
+			m.printf("\n")
+
+			if bookmark.Text != "" {
+				m.printf("%s\n", markdownQuote(bookmark.Text))
+			}
+
+			if bookmark.Annotation != "" {
+				if bookmark.Text != "" {
+					m.printf(">\n")
+				}
+
+				m.printf("%s\n", markdownQuote("Note: "+bookmark.Annotation))
+			}
+		}
+	}
+}
+
+// markdownCell escapes the text for a table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
+
+// markdownQuote returns the text as a block quote, keeping the paragraphs
+func markdownQuote(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for idx := range lines {
+		lines[idx] = strings.TrimRight("> "+strings.TrimSpace(lines[idx]), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// markdownDate returns the date of the storage time e.g. 2024-01-02
+func markdownDate(ts string) string {
+	if len(ts) < len(reportDateFmt) {
+		return ts
+	}
+
+	return ts[:len(reportDateFmt)]
+}
+
+// markdownHours returns the duration with the hours e.g. "2h 30m 0s (2.50 hours)"
+func markdownHours(seconds int) string {
+	return fmt.Sprintf("%s (%s hours)", HumanizeDurationShort(time.Duration(seconds)*time.Second), SecondsToHoursString(seconds))
+}
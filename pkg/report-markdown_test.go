@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMarkdownReport(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "/mnt/onboard/books/matilda.epub"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddBookmark("b1", testBookAID, testBookAID, bookmarkTypeNote, "", 0, 0, 0, "Your body is a sleeve", "Good",
+		time.Date(2024, 1, 2, 20, 5, 0, 0, time.UTC), time.Date(2024, 1, 2, 20, 5, 0, 0, time.UTC))
+
+	storage.AddContent(testBookBID, "Matilda | Roald", "Roald Dahl", "", "", 0, true, false, 10)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 20, 8, 0, 0, 0, time.UTC), 300)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 2, 4, 8, 0, 0, 0, time.UTC), 120)
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, WriteMarkdownReport(&buf, NewReport(NewStats(storage), 2024), MarkdownOptions{Highlights: true}))
+
+	report := buf.String()
+
+	assert.Contains(t, report, "# Reading Stats for 2024\n\n| | |\n|---|---:|\n| Finished books | 1 |\n")
+	assert.NotContains(t, report, "articles")
+	assert.Contains(t, report, "\n## January 2024\n\nFinished books: 1, time reading books: 15m 0s (0.25 hours)\n")
+	assert.Contains(t, report, "| Altered Carbon | Richard K. Morgan | finished | 10m 0s | 1 | 2024-01-02 | 2024-01-03 |\n")
+	assert.Contains(t, report, "| Matilda \\| Roald | Roald Dahl | started | 5m 0s | 1 | 2024-01-20 |  |\n")
+	assert.Contains(t, report, "| Matilda \\| Roald | Roald Dahl | continued | 2m 0s | 1 | 2024-01-20 |  |\n")
+	assert.Contains(t, report, "\n### Altered Carbon\n\n> Your body is a sleeve\n>\n> Note: Good\n")
+	assert.NotContains(t, report, "## March")
+}
@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data of a --template report, the Report of the year with the devices. See docs/Report-template.md
+type TemplateData struct {
+	Report
+
+	Devices []ReportDevice `json:"devices"`
+
+	// Generated is when the report was written e.g. 2024-01-02T20:00:00.000
+	Generated string `json:"generated"`
+}
+
+// ReportDevice is the reading on a device in the year. Model is empty for a device that was never synced e.g. an import
+type ReportDevice struct {
+	Device string `json:"device"`
+	Model  string `json:"model"`
+
+	Books    int `json:"books"`
+	Sessions int `json:"sessions"`
+	Seconds  int `json:"seconds"`
+}
+
+// templateFuncs are the functions available to a template
+var templateFuncs = map[string]any{
+	// hours of the seconds e.g. 5400 is "1.50"
+	"hours": SecondsToHoursString,
+
+	// duration of the seconds e.g. 5400 is "1h 30m 0s"
+	"duration": func(seconds int) string {
+		return HumanizeDurationShort(time.Duration(seconds) * time.Second)
+	},
+
+	// date of the time e.g. "2024-01-02T20:00:00.000" is "2024-01-02"
+	"date": markdownDate,
+
+	// monthName of the month number e.g. 1 is "January"
+	"monthName": func(month int) string {
+		if month < 1 || month > 12 {
+			return ""
+		}
+
+		return reportMonths[month]
+	},
+}
+
+// NewTemplateData returns the data of the year for a template
+func NewTemplateData(storage Storage, stats Stats, year int) TemplateData {
+	result := TemplateData{
+		Report:    NewReport(stats, year),
+		Devices:   make([]ReportDevice, 0),
+		Generated: time.Now().Format(StorageTimeFmt),
+	}
+
+	models := map[string]string{}
+	for _, device := range storage.Devices() {
+		models[device.Device] = device.Model
+	}
+
+	devices := map[string]*ReportDevice{}
+	books := map[string]map[string]bool{}
+
+	for _, book := range stats.Content {
+		for _, read := range book.Reads {
+			if readTime, _ := time.Parse(StorageTimeFmt, read.Time); readTime.Year() != year {
+				continue
+			}
+
+			device, exists := devices[read.Device]
+			if !exists {
+				device = &ReportDevice{Device: read.Device, Model: models[read.Device]}
+				devices[read.Device] = device
+				books[read.Device] = map[string]bool{}
+			}
+
+			device.Sessions++
+			device.Seconds += read.Duration
+			books[read.Device][book.BookID] = true
+		}
+	}
+
+	for name, device := range devices {
+		device.Books = len(books[name])
+		result.Devices = append(result.Devices, *device)
+	}
+
+	sort.Slice(result.Devices, func(i, j int) bool {
+		if result.Devices[i].Seconds != result.Devices[j].Seconds {
+			return result.Devices[i].Seconds > result.Devices[j].Seconds
+		}
+
+		return result.Devices[i].Device < result.Devices[j].Device
+	})
+
+	return result
+}
+
+// WriteTemplateReport executes the template file with the data. A .html or .htm file is an html/template so the
+// text of the books is escaped, any other file is a text/template
+func WriteTemplateReport(w io.Writer, templateFn string, data TemplateData) error {
+	templateBytes, err := os.ReadFile(templateFn)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(templateFn)
+
+	switch strings.ToLower(filepath.Ext(templateFn)) {
+	case ".html", ".htm":
+		tmpl, err := htmltemplate.New(name).Funcs(templateFuncs).Parse(string(templateBytes))
+		if err != nil {
+			return err
+		}
+
+		return tmpl.Execute(w, data)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(templateBytes))
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, data)
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTemplateReport(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testDeviceBID = "test-device-b"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+	)
+
+	dir := t.TempDir()
+
+	storage, err := OpenStorageOrCreate(filepath.Join(dir, "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddDevice(testDeviceAID, "Kobo Libra 2")
+	storage.AddContent(testBookAID, "Altered <Carbon>", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 5400)
+	storage.AddEvent(testBookAID, testDeviceBID, ReadEvent.String(), time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 6, 20, 0, 0, 0, time.UTC), 0)
+
+	data := NewTemplateData(storage, NewStats(storage), 2024)
+
+	assert.Equal(t, []ReportDevice{
+		{Device: testDeviceAID, Model: "Kobo Libra 2", Books: 1, Sessions: 1, Seconds: 5400},
+		{Device: testDeviceBID, Books: 1, Sessions: 1, Seconds: 600},
+	}, data.Devices)
+
+	tests := []struct {
+		name     string
+		fn       string
+		template string
+		expected string
+	}{
+		{
+			name:     "text",
+			fn:       "report.md.tmpl",
+			template: `{{ .Year }}{{ range .Books }} {{ .Title }} {{ duration .ReadSeconds }} {{ date .Finished }}{{ end }}{{ range .Devices }} {{ .Model }}{{ end }}`,
+			expected: "2024 Altered <Carbon> 1h 40m 0s 2024-01-06 Kobo Libra 2 ",
+		},
+		{
+			name:     "html is escaped",
+			fn:       "report.html",
+			template: `{{ range .Books }}<b>{{ .Title }}</b>{{ end }} {{ monthName 1 }} {{ hours .Totals.TotalSeconds }}`,
+			expected: "<b>Altered &lt;Carbon&gt;</b> January 1.67",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := filepath.Join(dir, tt.fn)
+			assert.NoError(t, os.WriteFile(fn, []byte(tt.template), 0o644))
+
+			buf := bytes.Buffer{}
+			assert.NoError(t, WriteTemplateReport(&buf, fn, data))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("template error", func(t *testing.T) {
+		fn := filepath.Join(dir, "bad.tmpl")
+		assert.NoError(t, os.WriteFile(fn, []byte(`{{ .Missing }}`), 0o644))
+
+		assert.Error(t, WriteTemplateReport(&bytes.Buffer{}, fn, data))
+	})
+}
@@ -0,0 +1,280 @@
+package pkg
+
+import (
+	"sort"
+	"time"
+
+	"github.com/snabb/isoweek"
+)
+
+// ReportVersion is the version of the Report json, it increases when a field is removed or changes meaning. Fields may
+// be added without a new version. See docs/Report-json.md
+const ReportVersion = 1
+
+const reportDateFmt = "2006-01-02"
+
+// Report status of a read-through
+const (
+	ReportStatusFinished   = "finished"
+	ReportStatusInProgress = "in_progress"
+)
+
+// Report is the stats of a year as written by stats and goals --mode json
+type Report struct {
+	Version int `json:"version"`
+	Year    int `json:"year"`
+
+	Totals ReportTotals `json:"totals"`
+
+	// Months are January to December, Weeks are the ISO weeks of the year
+	Months []ReportPeriod `json:"months"`
+	Weeks  []ReportPeriod `json:"weeks"`
+
+	// Books and Articles are the read-throughs finished in the year or read in the year and not finished in it
+	Books    []ReportBook `json:"books"`
+	Articles []ReportBook `json:"articles"`
+}
+
+// ReportTotals are the totals of the year. Times are seconds
+type ReportTotals struct {
+	BooksFinished    int `json:"books_finished"`
+	ArticlesFinished int `json:"articles_finished"`
+	BooksRead        int `json:"books_read"`
+	ArticlesRead     int `json:"articles_read"`
+	Sessions         int `json:"sessions"`
+
+	BookSeconds    int `json:"book_seconds"`
+	ArticleSeconds int `json:"article_seconds"`
+	TotalSeconds   int `json:"total_seconds"`
+
+	// WeeklyAverageSeconds is the average of the weeks until now
+	WeeklyAverageSeconds int `json:"weekly_average_seconds"`
+}
+
+// ReportPeriod is a month or ISO week. Start is the first day e.g. 2024-01-01. Read is the number of books or articles
+// with a session in the period. Times are seconds
+type ReportPeriod struct {
+	Month int    `json:"month,omitempty"`
+	Week  int    `json:"week,omitempty"`
+	Start string `json:"start"`
+
+	BooksFinished    int `json:"books_finished"`
+	ArticlesFinished int `json:"articles_finished"`
+	BooksRead        int `json:"books_read"`
+	ArticlesRead     int `json:"articles_read"`
+
+	BookSeconds    int `json:"book_seconds"`
+	ArticleSeconds int `json:"article_seconds"`
+	TotalSeconds   int `json:"total_seconds"`
+}
+
+// ReportBook is one read-through of a book or article. Sessions are all the sessions of the read-through, which may
+// start before the year. ReadSeconds is the total of the sessions and YearSeconds only those in the year
+type ReportBook struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	URL    string `json:"url,omitempty"`
+	ISBN   string `json:"isbn,omitempty"`
+	Words  int    `json:"words"`
+
+	Status string `json:"status"`
+
+	// ReadThrough is the number of the read-through of a re-read book
+	ReadThrough int `json:"read_through,omitempty"`
+
+	Started        string       `json:"started"`
+	Finished       string       `json:"finished,omitempty"`
+	FinishedSource FinishSource `json:"finished_source,omitempty"`
+	Estimated      bool         `json:"estimated,omitempty"`
+
+	Rating    int `json:"rating,omitempty"`
+	ReadCount int `json:"read_count,omitempty"`
+
+	ReadSeconds int `json:"read_seconds"`
+	YearSeconds int `json:"year_seconds"`
+
+	Sessions  []StatsRead     `json:"sessions"`
+	Bookmarks []StatsBookmark `json:"bookmarks"`
+}
+
+// NewReport returns the report of the year
+func NewReport(stats Stats, year int) Report {
+	result := Report{
+		Version:  ReportVersion,
+		Year:     year,
+		Months:   make([]ReportPeriod, 0, 12),
+		Weeks:    make([]ReportPeriod, 0, 53),
+		Books:    make([]ReportBook, 0),
+		Articles: make([]ReportBook, 0),
+	}
+
+	for month := 1; month <= 12; month++ {
+		monthStats := stats.Years[year].Months[month]
+
+		period := ReportPeriod{
+			Month:            month,
+			Start:            time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format(reportDateFmt),
+			BooksFinished:    len(monthStats.FinishedBooks),
+			ArticlesFinished: len(monthStats.FinishedArticles),
+			BooksRead:        len(monthStats.Books),
+			ArticlesRead:     len(monthStats.Articles),
+			BookSeconds:      stats.BooksSecondsReadMonth(year, month),
+			ArticleSeconds:   stats.ArticlesSecondsReadMonth(year, month),
+		}
+		period.TotalSeconds = period.BookSeconds + period.ArticleSeconds
+
+		result.Months = append(result.Months, period)
+
+		result.Totals.BooksFinished += period.BooksFinished
+		result.Totals.ArticlesFinished += period.ArticlesFinished
+		result.Totals.BookSeconds += period.BookSeconds
+		result.Totals.ArticleSeconds += period.ArticleSeconds
+	}
+
+	result.Totals.TotalSeconds = result.Totals.BookSeconds + result.Totals.ArticleSeconds
+
+	pastWeeks := 0
+	pastWeeksSeconds := 0
+
+	for week := 1; week <= 53; week++ {
+		weekStartTime := isoweek.StartTime(year, week, time.UTC)
+		if weekStartYear, _ := weekStartTime.ISOWeek(); weekStartYear != year {
+			break
+		}
+
+		weekStats := stats.Years[year].Weeks[week]
+
+		period := ReportPeriod{
+			Week:             week,
+			Start:            weekStartTime.Format(reportDateFmt),
+			BooksFinished:    len(weekStats.FinishedBooks),
+			ArticlesFinished: len(weekStats.FinishedArticles),
+			BooksRead:        len(weekStats.Books),
+			ArticlesRead:     len(weekStats.Articles),
+			BookSeconds:      stats.BooksSecondsReadWeek(year, week),
+			ArticleSeconds:   stats.ArticleSecondsReadWeek(year, week),
+		}
+		period.TotalSeconds = period.BookSeconds + period.ArticleSeconds
+
+		result.Weeks = append(result.Weeks, period)
+
+		if !weekStartTime.After(time.Now()) {
+			pastWeeks++
+			pastWeeksSeconds += period.TotalSeconds
+		}
+	}
+
+	if pastWeeks > 0 {
+		result.Totals.WeeklyAverageSeconds = pastWeeksSeconds / pastWeeks
+	}
+
+	for _, book := range stats.Content {
+		if readInYear(book.Reads, year) {
+			if book.IsBook {
+				result.Totals.BooksRead++
+			} else {
+				result.Totals.ArticlesRead++
+			}
+
+			result.Totals.Sessions += book.NumSessionsInYear(year)
+		}
+
+		for idx := range book.ReadThroughs {
+			reportBook, ok := newReportBook(book, idx, year)
+			if !ok {
+				continue
+			}
+
+			if book.IsBook {
+				result.Books = append(result.Books, reportBook)
+			} else {
+				result.Articles = append(result.Articles, reportBook)
+			}
+		}
+	}
+
+	sortReportBooks(result.Books)
+	sortReportBooks(result.Articles)
+
+	return result
+}
+
+// newReportBook returns the read-through when it was finished in the year, or read in the year and not finished in it
+func newReportBook(book StatsBook, idx, year int) (ReportBook, bool) {
+	readThrough := book.ReadThroughs[idx]
+
+	yearSeconds := 0
+	for _, read := range readThrough.Reads {
+		if readTime, _ := time.Parse(StorageTimeFmt, read.Time); readTime.Year() == year {
+			yearSeconds += read.Duration
+		}
+	}
+
+	finishedTime, _ := time.Parse(StorageTimeFmt, readThrough.FinishedTime)
+	finishedInYear := readThrough.IsFinished && readThrough.FinishedTime != "" && finishedTime.Year() == year
+
+	if !finishedInYear && !readInYear(readThrough.Reads, year) {
+		return ReportBook{}, false
+	}
+
+	result := ReportBook{
+		ID:          book.BookID,
+		Title:       book.Title,
+		Author:      book.Author,
+		URL:         book.URL,
+		ISBN:        book.ISBN,
+		Words:       book.Words,
+		Status:      ReportStatusInProgress,
+		Started:     readThrough.Start,
+		Rating:      book.Rating,
+		ReadCount:   book.ReadCount,
+		ReadSeconds: readThrough.ReadSeconds(),
+		YearSeconds: yearSeconds,
+		Sessions:    readThrough.Reads,
+		Bookmarks:   book.Bookmarks,
+	}
+
+	if len(book.ReadThroughs) > 1 {
+		result.ReadThrough = idx + 1
+	}
+
+	if finishedInYear {
+		result.Status = ReportStatusFinished
+		result.Finished = readThrough.FinishedTime
+		result.FinishedSource = readThrough.FinishedSource
+		result.Estimated = readThrough.FinishedSource.IsEstimated()
+	}
+
+	return result, true
+}
+
+// readInYear is true when a read is in the year
+func readInYear(reads []StatsRead, year int) bool {
+	for _, read := range reads {
+		if readTime, _ := time.Parse(StorageTimeFmt, read.Time); readTime.Year() == year {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortReportBooks sorts the finished books by finish then the books in progress by start
+func sortReportBooks(books []ReportBook) {
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Status != books[j].Status {
+			return books[i].Status == ReportStatusFinished
+		}
+
+		if books[i].Finished != books[j].Finished {
+			return books[i].Finished < books[j].Finished
+		}
+
+		if books[i].Started != books[j].Started {
+			return books[i].Started < books[j].Started
+		}
+
+		return books[i].ID < books[j].ID
+	})
+}
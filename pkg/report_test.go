@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReport(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "/mnt/onboard/books/matilda.epub"
+		testArticleID = "articles/a"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), 60)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+
+	storage.AddContent(testBookBID, "Matilda", "Roald Dahl", "", "", 0, true, false, 10)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC), 300)
+
+	storage.AddContent(testArticleID, "Article", "", "test.com", "", 100, false, false, 10)
+	storage.AddEvent(testArticleID, testDeviceAID, ReadEvent.String(), time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC), 120)
+
+	report := NewReport(NewStats(storage), 2024)
+
+	assert.Equal(t, ReportVersion, report.Version)
+	assert.Equal(t, 2024, report.Year)
+
+	assert.Equal(t, 1, report.Totals.BooksFinished)
+	assert.Equal(t, 2, report.Totals.BooksRead)
+	assert.Equal(t, 0, report.Totals.ArticlesRead)
+	assert.Equal(t, 2, report.Totals.Sessions)
+	assert.Equal(t, 900, report.Totals.BookSeconds)
+	assert.Equal(t, 900, report.Totals.TotalSeconds)
+
+	assert.Len(t, report.Months, 12)
+	assert.Equal(t, ReportPeriod{Month: 1, Start: "2024-01-01", BooksFinished: 1, BooksRead: 1, BookSeconds: 600, TotalSeconds: 600}, report.Months[0])
+	assert.Equal(t, 300, report.Months[2].BookSeconds)
+
+	assert.Len(t, report.Weeks, 52)
+	assert.Equal(t, ReportPeriod{Week: 1, Start: "2024-01-01", BooksFinished: 1, BooksRead: 1, BookSeconds: 600, TotalSeconds: 600}, report.Weeks[0])
+
+	assert.Len(t, report.Books, 2)
+	assert.Equal(t, testBookAID, report.Books[0].ID)
+	assert.Equal(t, ReportStatusFinished, report.Books[0].Status)
+	assert.Equal(t, "2023-12-31T20:00:00.000", report.Books[0].Started)
+	assert.Equal(t, "2024-01-03T20:00:00.000", report.Books[0].Finished)
+	assert.Equal(t, FinishSourceEvent, report.Books[0].FinishedSource)
+	assert.Equal(t, 660, report.Books[0].ReadSeconds)
+	assert.Equal(t, 600, report.Books[0].YearSeconds)
+	assert.Len(t, report.Books[0].Sessions, 2)
+
+	assert.Equal(t, testBookBID, report.Books[1].ID)
+	assert.Equal(t, ReportStatusInProgress, report.Books[1].Status)
+	assert.Empty(t, report.Books[1].Finished)
+
+	assert.Empty(t, report.Articles)
+}
@@ -0,0 +1,461 @@
+package pkg
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//go:embed files/dashboard.html
+var dashboardHTML []byte
+
+// Server is the http dashboard and json API of the stats. The storage is reloaded when the file changes
+type Server struct {
+	fn      string
+	options StatsOptions
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	storage Storage
+	stats   Stats
+
+	mux *http.ServeMux
+}
+
+// ServeYear is the summary of a year in /api/years
+type ServeYear struct {
+	Year             int `json:"year"`
+	BooksFinished    int `json:"books_finished"`
+	ArticlesFinished int `json:"articles_finished"`
+	TotalSeconds     int `json:"total_seconds"`
+}
+
+// ServeBook is the summary of a book or article in /api/books
+type ServeBook struct {
+	ID             string       `json:"id"`
+	Title          string       `json:"title"`
+	Author         string       `json:"author"`
+	IsBook         bool         `json:"is_book"`
+	IsFinished     bool         `json:"is_finished"`
+	FinishedTime   string       `json:"finished_time,omitempty"`
+	FinishedSource FinishSource `json:"finished_source,omitempty"`
+	Rating         int          `json:"rating,omitempty"`
+	ReadCount      int          `json:"read_count,omitempty"`
+	FirstRead      string       `json:"first_read,omitempty"`
+	LastRead       string       `json:"last_read,omitempty"`
+	Sessions       int          `json:"sessions"`
+	ReadSeconds    int          `json:"read_seconds"`
+	Shelves        []string     `json:"shelves,omitempty"`
+}
+
+// ServeSession is a reading session in /api/sessions
+type ServeSession struct {
+	StatsRead
+
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	IsBook bool   `json:"is_book"`
+}
+
+// NewServer loads the storage file and returns the Server
+func NewServer(fn string, options StatsOptions) (*Server, error) {
+	s := &Server{fn: fn, options: options}
+
+	if _, _, err := s.load(); err != nil {
+		return nil, err
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /{$}", s.handleDashboard)
+	s.mux.HandleFunc("GET /readstat.css", s.handleCSS)
+	s.mux.HandleFunc("GET /api/years", s.handleYears)
+	s.mux.HandleFunc("GET /api/years/{year}", s.handleYear)
+	s.mux.HandleFunc("GET /api/books", s.handleBooks)
+	s.mux.HandleFunc("GET /api/books/{id}", s.handleBook)
+	s.mux.HandleFunc("GET /api/sessions", s.handleSessions)
+	s.mux.HandleFunc("GET /api/devices", s.handleDevices)
+	s.mux.HandleFunc("GET /api/shelves", s.handleShelves)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// load returns the storage and stats, reloading them when the storage file has changed. A file that can not be read
+// e.g. while sync is writing it keeps the previous storage once loaded
+func (s *Server) load() (Storage, Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.fn)
+	if err != nil {
+		if s.storage != nil {
+			return s.storage, s.stats, nil
+		}
+
+		return nil, Stats{}, err
+	}
+
+	if s.storage != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.storage, s.stats, nil
+	}
+
+	storage, err := OpenStorageOrCreate(s.fn)
+	if err != nil {
+		if s.storage != nil {
+			return s.storage, s.stats, nil
+		}
+
+		return nil, Stats{}, err
+	}
+
+	s.storage = storage
+	s.stats = NewStatsWithOptions(storage, s.options)
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	return s.storage, s.stats, nil
+}
+
+// filtered returns the storage and stats of the ?device= and ?shelf= filters of the request
+func (s *Server) filtered(r *http.Request) (Storage, Stats, error) {
+	storage, stats, err := s.load()
+	if err != nil {
+		return nil, Stats{}, err
+	}
+
+	device := r.URL.Query().Get("device")
+	shelf := r.URL.Query().Get("shelf")
+
+	if device == "" && shelf == "" {
+		return storage, stats, nil
+	}
+
+	storage = newFilterStorage(storage, device, shelf)
+
+	return storage, NewStatsWithOptions(storage, s.options), nil
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboardHTML)
+}
+
+func (s *Server) handleCSS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	_, _ = io.WriteString(w, readstatCSS)
+}
+
+func (s *Server) handleYears(w http.ResponseWriter, r *http.Request) {
+	_, stats, err := s.filtered(r)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := make([]ServeYear, 0, len(stats.Years))
+
+	for _, year := range statsYears(stats) {
+		report := NewReport(stats, year)
+
+		result = append(result, ServeYear{
+			Year:             year,
+			BooksFinished:    report.Totals.BooksFinished,
+			ArticlesFinished: report.Totals.ArticlesFinished,
+			TotalSeconds:     report.Totals.TotalSeconds,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleYear(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid year: %s", r.PathValue("year")))
+		return
+	}
+
+	_, stats, err := s.filtered(r)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NewReport(stats, year))
+}
+
+func (s *Server) handleBooks(w http.ResponseWriter, r *http.Request) {
+	storage, stats, err := s.filtered(r)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	shelves := shelvesByContent(storage, NewContentIdentity(storage.Contents()))
+
+	result := make([]ServeBook, 0, len(stats.Content))
+
+	for cid, book := range stats.Content {
+		result = append(result, ServeBook{
+			ID:             cid,
+			Title:          book.Title,
+			Author:         book.Author,
+			IsBook:         book.IsBook,
+			IsFinished:     book.IsFinished,
+			FinishedTime:   book.FinishedTime,
+			FinishedSource: book.FinishedSource,
+			Rating:         book.Rating,
+			ReadCount:      book.ReadCount,
+			FirstRead:      book.FirstReadTime(),
+			LastRead:       book.LastReadTime(),
+			Sessions:       len(book.Reads),
+			ReadSeconds:    book.ReadSeconds(),
+			Shelves:        shelves[cid],
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Title != result[j].Title {
+			return result[i].Title < result[j].Title
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
+	storage, stats, err := s.filtered(r)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	detail, err := FindBook(storage, stats, r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, ErrContentNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrContentAmbiguous):
+			status = http.StatusConflict
+		}
+
+		writeJSONError(w, status, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	var (
+		options ExportOptions
+		err     error
+	)
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		if options.From, err = ParseManualTime(from); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid from: %w", err))
+			return
+		}
+	}
+
+	if to := r.URL.Query().Get("to"); to != "" {
+		if options.To, err = ParseManualTimeEnd(to); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid to: %w", err))
+			return
+		}
+	}
+
+	_, stats, err := s.filtered(r)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := make([]ServeSession, 0)
+
+	for cid, book := range stats.Content {
+		for _, read := range book.Reads {
+			if !options.inRange(read.Time) {
+				continue
+			}
+
+			result = append(result, ServeSession{
+				StatsRead: read,
+				ID:        cid,
+				Title:     book.Title,
+				Author:    book.Author,
+				IsBook:    book.IsBook,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Time != result[j].Time {
+			return result[i].Time < result[j].Time
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleDevices(w http.ResponseWriter, _ *http.Request) {
+	storage, _, err := s.load()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	devices := storage.Devices()
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].Device < devices[j].Device
+	})
+
+	writeJSON(w, http.StatusOK, devices)
+}
+
+func (s *Server) handleShelves(w http.ResponseWriter, _ *http.Request) {
+	storage, _, err := s.load()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := make([]string, 0)
+
+	for _, shelf := range storage.Shelfs() {
+		if !shelf.IsDeleted && !slices.Contains(result, shelf.Name) {
+			result = append(result, shelf.Name)
+		}
+	}
+
+	sort.Strings(result)
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	storage, stats, err := s.load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", MetricsContentType)
+	_ = WriteMetrics(w, stats, storage.Devices(), time.Now())
+}
+
+// statsYears returns the years of the stats in order
+func statsYears(stats Stats) []int {
+	result := make([]int, 0, len(stats.Years))
+
+	for year := range stats.Years {
+		result = append(result, year)
+	}
+
+	sort.Ints(result)
+
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// filterStorage is a read only view of a Storage with only the events of a device and the contents of a shelf
+type filterStorage struct {
+	Storage
+
+	device   string
+	contents map[string]bool
+}
+
+func newFilterStorage(base Storage, device, shelf string) *filterStorage {
+	result := &filterStorage{Storage: base, device: device}
+
+	if shelf != "" {
+		identity := NewContentIdentity(base.Contents())
+		shelves := shelvesByContent(base, identity)
+
+		result.contents = map[string]bool{}
+
+		for _, content := range base.Contents() {
+			if slices.Contains(shelves[identity.Canonical(content.ID)], shelf) {
+				result.contents[content.ID] = true
+			}
+		}
+	}
+
+	return result
+}
+
+// Contents returns the contents on the shelf with events of the device
+func (s *filterStorage) Contents() []StorageContent {
+	result := make([]StorageContent, 0)
+
+	for _, content := range s.Storage.Contents() {
+		if s.contents != nil && !s.contents[content.ID] {
+			continue
+		}
+
+		if s.device != "" && len(s.Events(content.ID)) == 0 {
+			continue
+		}
+
+		result = append(result, content)
+	}
+
+	return result
+}
+
+// Events returns the events of the device
+func (s *filterStorage) Events(cID string) []StorageEvents {
+	events := s.Storage.Events(cID)
+	if s.device == "" {
+		return events
+	}
+
+	result := make([]StorageEvents, 0, len(events))
+
+	for _, event := range events {
+		if event.Device == s.device {
+			result = append(result, event)
+		}
+	}
+
+	return result
+}
+
+// Save never writes the filtered view
+func (s *filterStorage) Save() error {
+	return nil
+}
@@ -0,0 +1,151 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testDeviceBID = "test-device-b"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "/mnt/onboard/books/matilda.epub"
+	)
+
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "readstat.json")
+
+	storage, err := OpenStorageOrCreate(fn)
+	assert.NoError(t, err)
+
+	storage.AddDevice(testDeviceAID, "Libra 2")
+	storage.AddDevice(testDeviceBID, "Clara HD")
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddShelf("shelf-id", "Science Fiction", "Science Fiction", "UserTag", false)
+	storage.AddShelfContent("Science Fiction", testBookAID, false)
+
+	storage.AddContent(testBookBID, "Matilda", "Roald Dahl", "", "", 0, true, false, 10)
+	storage.AddEvent(testBookBID, testDeviceBID, ReadEvent.String(), time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC), 300)
+	assert.NoError(t, storage.Save())
+
+	server, err := NewServer(fn, DefaultStatsOptions)
+	assert.NoError(t, err)
+
+	get := func(t *testing.T, path string, v any) int {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if v != nil {
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), v))
+		}
+
+		return recorder.Code
+	}
+
+	t.Run("dashboard", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "<title>Reading Stats</title>")
+	})
+
+	t.Run("years", func(t *testing.T) {
+		var years []ServeYear
+		assert.Equal(t, http.StatusOK, get(t, "/api/years", &years))
+		assert.Equal(t, []ServeYear{{Year: 2024, BooksFinished: 1, TotalSeconds: 900}}, years)
+
+		var report Report
+		assert.Equal(t, http.StatusOK, get(t, "/api/years/2024", &report))
+		assert.Equal(t, 1, report.Totals.BooksFinished)
+		assert.Len(t, report.Books, 2)
+
+		assert.Equal(t, http.StatusBadRequest, get(t, "/api/years/abc", nil))
+	})
+
+	t.Run("books", func(t *testing.T) {
+		var books []ServeBook
+		assert.Equal(t, http.StatusOK, get(t, "/api/books", &books))
+		assert.Len(t, books, 2)
+		assert.Equal(t, "Altered Carbon", books[0].Title)
+		assert.Equal(t, []string{"Science Fiction"}, books[0].Shelves)
+		assert.Equal(t, 600, books[0].ReadSeconds)
+
+		var detail BookDetail
+		assert.Equal(t, http.StatusOK, get(t, "/api/books/"+url.PathEscape(testBookAID), &detail))
+		assert.Equal(t, "Altered Carbon", detail.Title)
+
+		assert.Equal(t, http.StatusOK, get(t, "/api/books/matilda", &detail))
+		assert.Equal(t, testBookBID, detail.BookID)
+
+		var errResult map[string]string
+		assert.Equal(t, http.StatusNotFound, get(t, "/api/books/missing", &errResult))
+		assert.Contains(t, errResult["error"], "missing")
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		var sessions []ServeSession
+		assert.Equal(t, http.StatusOK, get(t, "/api/sessions", &sessions))
+		assert.Len(t, sessions, 2)
+
+		assert.Equal(t, http.StatusOK, get(t, "/api/sessions?from=2024-01-01&to=2024-01-02", &sessions))
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, "Altered Carbon", sessions[0].Title)
+		assert.Equal(t, testDeviceAID, sessions[0].Device)
+
+		assert.Equal(t, http.StatusBadRequest, get(t, "/api/sessions?from=yesterday", nil))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		var books []ServeBook
+		assert.Equal(t, http.StatusOK, get(t, "/api/books?device="+testDeviceBID, &books))
+		assert.Len(t, books, 1)
+		assert.Equal(t, "Matilda", books[0].Title)
+
+		assert.Equal(t, http.StatusOK, get(t, "/api/books?shelf=Science+Fiction", &books))
+		assert.Len(t, books, 1)
+		assert.Equal(t, "Altered Carbon", books[0].Title)
+
+		var devices []StorageDevice
+		assert.Equal(t, http.StatusOK, get(t, "/api/devices", &devices))
+		assert.Len(t, devices, 2)
+
+		var shelves []string
+		assert.Equal(t, http.StatusOK, get(t, "/api/shelves", &shelves))
+		assert.Equal(t, []string{"Science Fiction"}, shelves)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, MetricsContentType, recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Body.String(), `kobo_readstat_finished{type="book",year="2024"} 1`)
+	})
+
+	t.Run("reload when the storage changes", func(t *testing.T) {
+		storage.AddEvent(testBookBID, testDeviceBID, ReadEvent.String(), time.Date(2025, 1, 5, 8, 0, 0, 0, time.UTC), 300)
+		assert.NoError(t, storage.Save())
+
+		later := time.Now().Add(time.Minute)
+		assert.NoError(t, os.Chtimes(fn, later, later))
+
+		var years []ServeYear
+		assert.Equal(t, http.StatusOK, get(t, "/api/years", &years))
+		assert.Len(t, years, 2)
+	})
+}
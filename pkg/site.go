@@ -0,0 +1,352 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const siteCSSFn = "style.css"
+
+//go:embed files/site.html
+var siteTemplate string
+
+// siteDirs are the directories of the generated pages, pages in them that are no longer generated are removed
+var siteDirs = []string{"years", "books", "authors", "shelves"}
+
+// SiteResult counts the pages of the site and the files that changed
+type SiteResult struct {
+	Pages   int `json:"pages"`
+	Written int `json:"written"`
+	Removed int `json:"removed"`
+}
+
+// sitePage is the data of every page. Root is the relative path to the top of the site e.g. "../"
+type sitePage struct {
+	Root  string
+	Title string
+	Years []int
+
+	Page any
+}
+
+type siteIndex struct {
+	Totals  ReportTotals
+	Years   []Report
+	Authors []siteList
+	Shelves []siteList
+}
+
+type siteYear struct {
+	Report
+
+	FinishedChart    template.HTML
+	ReadingTimeChart template.HTML
+}
+
+// siteList is an author or shelf page
+type siteList struct {
+	Name  string
+	Books []StatsBook
+}
+
+// siteWriter renders the pages and writes only the files that changed
+type siteWriter struct {
+	dir  string
+	tmpl *template.Template
+
+	books   map[string]string
+	authors map[string]string
+	shelves map[string]string
+
+	written map[string]bool
+	result  SiteResult
+}
+
+// WriteSite writes a static website of the stats to dir: an index with the all-time totals and every year, a page
+// per year, book, author and shelf, all linked together. Files are only written when they change and pages that are
+// no longer generated are removed, so regenerating after a sync only touches what changed.
+func WriteSite(storage Storage, stats Stats, dir string) (SiteResult, error) {
+	identity := NewContentIdentity(storage.Contents())
+	shelves := shelvesByContent(storage, identity)
+
+	books := make([]StatsBook, 0, len(stats.Content))
+	for _, book := range stats.Content {
+		if book.IsBook {
+			books = append(books, book)
+		}
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Title != books[j].Title {
+			return books[i].Title < books[j].Title
+		}
+
+		return books[i].BookID < books[j].BookID
+	})
+
+	w := &siteWriter{
+		dir:     dir,
+		books:   map[string]string{},
+		authors: map[string]string{},
+		shelves: map[string]string{},
+		written: map[string]bool{},
+	}
+
+	authorBooks := map[string]*siteList{}
+	shelfBooks := map[string]*siteList{}
+
+	for _, book := range books {
+		w.books[book.BookID] = siteBookPath(book)
+
+		authorPath := w.authorPath(book.Author)
+		if _, exists := authorBooks[authorPath]; !exists {
+			authorBooks[authorPath] = &siteList{Name: siteAuthorName(book.Author)}
+		}
+
+		authorBooks[authorPath].Books = append(authorBooks[authorPath].Books, book)
+
+		for _, shelfName := range shelves[book.BookID] {
+			shelfPath := w.shelfPath(shelfName)
+			if _, exists := shelfBooks[shelfPath]; !exists {
+				shelfBooks[shelfPath] = &siteList{Name: shelfName}
+			}
+
+			shelfBooks[shelfPath].Books = append(shelfBooks[shelfPath].Books, book)
+		}
+	}
+
+	tmpl, err := template.New("site").Funcs(templateFuncs).Funcs(template.FuncMap{
+		"bookLink":   func(root, cid string) string { return root + w.books[cid] },
+		"authorLink": func(root, author string) string { return root + w.authorPath(author) },
+		"shelfLink":  func(root, name string) string { return root + w.shelfPath(name) },
+		"yearLink":   func(root string, year int) string { return root + siteYearPath(year) },
+	}).Parse(siteTemplate)
+	if err != nil {
+		return w.result, err
+	}
+
+	w.tmpl = tmpl
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return w.result, err
+	}
+
+	if err := w.writeFile(siteCSSFn, []byte(readstatCSS)); err != nil {
+		return w.result, err
+	}
+
+	// Years with reading, newest first
+	years := make([]int, 0, len(stats.Years))
+	reports := make([]Report, 0, len(stats.Years))
+
+	for year := range stats.Years {
+		report := NewReport(stats, year)
+		if report.Totals.TotalSeconds > 0 || report.Totals.BooksFinished > 0 || report.Totals.ArticlesFinished > 0 {
+			years = append(years, year)
+			reports = append(reports, report)
+		}
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Year > reports[j].Year
+	})
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+
+	index := siteIndex{Years: reports, Authors: siteLists(authorBooks), Shelves: siteLists(shelfBooks)}
+
+	for _, report := range reports {
+		index.Totals.BooksFinished += report.Totals.BooksFinished
+		index.Totals.ArticlesFinished += report.Totals.ArticlesFinished
+		index.Totals.Sessions += report.Totals.Sessions
+		index.Totals.BookSeconds += report.Totals.BookSeconds
+		index.Totals.ArticleSeconds += report.Totals.ArticleSeconds
+		index.Totals.TotalSeconds += report.Totals.TotalSeconds
+	}
+
+	for _, book := range stats.Content {
+		if len(book.Reads) > 0 {
+			if book.IsBook {
+				index.Totals.BooksRead++
+			} else {
+				index.Totals.ArticlesRead++
+			}
+		}
+	}
+
+	if err := w.writePage("index.html", "index", sitePage{Title: "Reading Stats", Years: years, Page: index}); err != nil {
+		return w.result, err
+	}
+
+	for _, report := range reports {
+		page := sitePage{Root: "../", Title: fmt.Sprintf(titleFmt, report.Year), Years: years, Page: newSiteYear(report)}
+		if err := w.writePage(siteYearPath(report.Year), "year", page); err != nil {
+			return w.result, err
+		}
+	}
+
+	for _, book := range books {
+		page := sitePage{Root: "../", Title: book.Title, Years: years, Page: newBookDetail(storage, identity, shelves, book)}
+		if err := w.writePage(w.books[book.BookID], "book", page); err != nil {
+			return w.result, err
+		}
+	}
+
+	for path, list := range authorBooks {
+		if err := w.writePage(path, "list", sitePage{Root: "../", Title: list.Name, Years: years, Page: *list}); err != nil {
+			return w.result, err
+		}
+	}
+
+	for path, list := range shelfBooks {
+		page := sitePage{Root: "../", Title: "Shelf: " + list.Name, Years: years, Page: *list}
+		if err := w.writePage(path, "list", page); err != nil {
+			return w.result, err
+		}
+	}
+
+	return w.result, w.removeStale()
+}
+
+func newSiteYear(report Report) siteYear {
+	labels := make([]string, 0, 12)
+	finished := make([]float64, 0, 12)
+	articles := make([]float64, 0, 12)
+	hours := make([]float64, 0, 12)
+
+	for _, month := range report.Months {
+		labels = append(labels, reportMonths[month.Month][:3])
+		finished = append(finished, float64(month.BooksFinished))
+		articles = append(articles, float64(month.ArticlesFinished))
+		hours = append(hours, float64(month.TotalSeconds)/3600)
+	}
+
+	return siteYear{
+		Report: report,
+		FinishedChart: svgChart("Books & Articles", labels, []chartSeries{
+			{Label: "Books Read", Values: finished, Color: "rgb(255, 99, 132)"},
+			{Label: "Articles Read", Values: articles, Color: "rgb(54, 162, 235)", Line: true},
+		}),
+		ReadingTimeChart: svgChart("Hours Reading", labels, []chartSeries{
+			{Label: "Hours Reading", Values: hours, Color: "rgb(75, 192, 192)", Line: true},
+		}),
+	}
+}
+
+// writePage renders the page with the template and writes it if it changed
+func (w *siteWriter) writePage(path, templateName string, page sitePage) error {
+	buf := bytes.Buffer{}
+	if err := w.tmpl.ExecuteTemplate(&buf, templateName, page); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	w.result.Pages++
+
+	return w.writeFile(path, buf.Bytes())
+}
+
+// writeFile writes the file unless it already has the content
+func (w *siteWriter) writeFile(path string, content []byte) error {
+	w.written[path] = true
+
+	fn := filepath.Join(w.dir, filepath.FromSlash(path))
+
+	if existing, err := os.ReadFile(fn); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0o755); err != nil {
+		return err
+	}
+
+	w.result.Written++
+
+	return os.WriteFile(fn, content, 0o644)
+}
+
+// removeStale removes the pages of the site directories that were not generated e.g. a book that was deleted
+func (w *siteWriter) removeStale() error {
+	for _, dir := range siteDirs {
+		entries, err := os.ReadDir(filepath.Join(w.dir, dir))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			path := dir + "/" + entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".html") || w.written[path] {
+				continue
+			}
+
+			if err := os.Remove(filepath.Join(w.dir, dir, entry.Name())); err != nil {
+				return err
+			}
+
+			w.result.Removed++
+		}
+	}
+
+	return nil
+}
+
+func (w *siteWriter) authorPath(author string) string {
+	return "authors/" + siteSlug(siteAuthorName(author)) + ".html"
+}
+
+func (w *siteWriter) shelfPath(name string) string {
+	return "shelves/" + siteSlug(name) + ".html"
+}
+
+func siteYearPath(year int) string {
+	return "years/" + strconv.Itoa(year) + ".html"
+}
+
+// siteBookPath is the title with a hash of the content ID so books with the same title have their own page
+func siteBookPath(book StatsBook) string {
+	sum := sha256.Sum256([]byte(book.BookID))
+
+	return "books/" + siteSlug(book.Title) + "-" + hex.EncodeToString(sum[:4]) + ".html"
+}
+
+func siteAuthorName(author string) string {
+	if strings.TrimSpace(author) == "" {
+		return "Unknown"
+	}
+
+	return author
+}
+
+// siteSlug is the slug of the name for a file name, "untitled" when it has no letters or digits
+func siteSlug(name string) string {
+	if slug := slugify(name); slug != "" {
+		return slug
+	}
+
+	return "untitled"
+}
+
+// siteLists returns the lists sorted by name
+func siteLists(lists map[string]*siteList) []siteList {
+	result := make([]siteList, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, *list)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+
+	return result
+}
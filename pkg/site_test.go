@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSite(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "/mnt/onboard/books/altered-carbon.epub"
+		testBookBID   = "/mnt/onboard/books/matilda.epub"
+	)
+
+	dir := t.TempDir()
+	siteDir := filepath.Join(dir, "site")
+
+	storage, err := OpenStorageOrCreate(filepath.Join(dir, "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Altered Carbon", "Richard K. Morgan", "", "", 550, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, Progress50Event.String(), time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddBookmark("b1", testBookAID, testBookAID, bookmarkTypeHighlight, "", 0, 0, 0, "Your body is a sleeve", "",
+		time.Date(2024, 1, 2, 20, 5, 0, 0, time.UTC), time.Date(2024, 1, 2, 20, 5, 0, 0, time.UTC))
+	storage.AddShelf("shelf-id", "Science Fiction", "Science Fiction", "UserTag", false)
+	storage.AddShelfContent("Science Fiction", testBookAID, false)
+
+	storage.AddContent(testBookBID, "Matilda", "", "", "", 0, true, false, 10)
+	storage.AddEvent(testBookBID, testDeviceAID, ReadEvent.String(), time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC), 300)
+
+	result, err := WriteSite(storage, NewStats(storage), siteDir)
+	assert.NoError(t, err)
+	assert.Equal(t, SiteResult{Pages: 8, Written: 9}, result)
+
+	bookPath := siteBookPath(StatsBook{BookID: testBookAID, Title: "Altered Carbon"})
+
+	for _, path := range []string{"index.html", "style.css", "years/2023.html", "years/2024.html", bookPath,
+		"authors/richard-k-morgan.html", "authors/unknown.html", "shelves/science-fiction.html"} {
+		assert.FileExists(t, filepath.Join(siteDir, path))
+	}
+
+	index := readSiteFile(t, siteDir, "index.html")
+	assert.Contains(t, index, `<a href="years/2024.html">2024</a>`)
+	assert.Contains(t, index, `<a href="authors/richard-k-morgan.html">Richard K. Morgan</a> (1)`)
+	assert.Contains(t, index, `<a href="shelves/science-fiction.html">Science Fiction</a> (1)`)
+
+	year := readSiteFile(t, siteDir, "years/2024.html")
+	assert.Contains(t, year, `<link rel="stylesheet" href="../style.css">`)
+	assert.Contains(t, year, `<a href="../`+bookPath+`"><b>Altered Carbon</b></a>`)
+	assert.Contains(t, year, `<svg class="chart"`)
+
+	book := readSiteFile(t, siteDir, bookPath)
+	assert.Contains(t, book, `<a href="../shelves/science-fiction.html">Science Fiction</a>`)
+	assert.Contains(t, book, "<td>50%</td>")
+	assert.Contains(t, book, "<p>Your body is a sleeve</p>")
+
+	t.Run("unchanged pages are not written", func(t *testing.T) {
+		result, err := WriteSite(storage, NewStats(storage), siteDir)
+		assert.NoError(t, err)
+		assert.Equal(t, SiteResult{Pages: 8}, result)
+	})
+
+	t.Run("stale pages are removed", func(t *testing.T) {
+		storage.AddShelfContent("Science Fiction", testBookAID, true)
+
+		result, err := WriteSite(storage, NewStats(storage), siteDir)
+		assert.NoError(t, err)
+		assert.Equal(t, SiteResult{Pages: 7, Written: 2, Removed: 1}, result)
+		assert.NoFileExists(t, filepath.Join(siteDir, "shelves/science-fiction.html"))
+	})
+}
+
+func readSiteFile(t *testing.T, dir, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	assert.NoError(t, err)
+
+	return strings.ReplaceAll(string(content), "\r\n", "\n")
+}
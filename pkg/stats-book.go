@@ -7,12 +7,25 @@ type StatsBook struct {
 	Title  string `json:"title"`
 	Author string `json:"author"`
 	URL    string `json:"url"`
+	ISBN   string `json:"isbn,omitempty"`
+
+	// Aliases are the content IDs of the same book on other devices or paths (including BookID)
+	Aliases []string `json:"aliases,omitempty"`
 
 	Words int `json:"words"`
 
-	IsBook       bool   `json:"is_book"`
-	IsFinished   bool   `json:"is_finished"`
-	FinishedTime string `json:"finished_time"`
+	IsBook         bool         `json:"is_book"`
+	IsFinished     bool         `json:"is_finished"`
+	FinishedTime   string       `json:"finished_time"`
+	FinishedSource FinishSource `json:"finished_source,omitempty"`
+
+	// Rating (1-5, 0 unrated) is from imports. ReadCount is the finished read-throughs or the imported read count if more
+	Rating    int `json:"rating,omitempty"`
+	ReadCount int `json:"read_count,omitempty"`
+
+	// ReadThrough is the number of the read-through when the book is one finished entry of a re-read book
+	ReadThrough  int                `json:"read_through,omitempty"`
+	ReadThroughs []StatsReadThrough `json:"read_throughs,omitempty"`
 
 	Reads     []StatsRead     `json:"reads"`
 	Bookmarks []StatsBookmark `json:"bookmarks"`
@@ -21,6 +34,7 @@ type StatsBook struct {
 type StatsRead struct {
 	Time     string `json:"time"`
 	Duration int    `json:"duration"`
+	Device   string `json:"device,omitempty"`
 }
 
 type StatsBookmark struct {
@@ -35,6 +49,13 @@ type StatsBookmark struct {
 	Type        string `json:"type,omitempty"`
 }
 
+// Span returns the start and end time of the read
+func (r StatsRead) Span() (time.Time, time.Time) {
+	start, _ := time.Parse(StorageTimeFmt, r.Time)
+
+	return start, start.Add(time.Duration(r.Duration) * time.Second)
+}
+
 func (b StatsBook) FirstReadTime() string {
 	result := ""
 
@@ -47,6 +68,18 @@ func (b StatsBook) FirstReadTime() string {
 	return result
 }
 
+func (b StatsBook) LastReadTime() string {
+	result := ""
+
+	for idx := range b.Reads {
+		if b.Reads[idx].Time > result {
+			result = b.Reads[idx].Time
+		}
+	}
+
+	return result
+}
+
 func (b StatsBook) ReadSeconds() int {
 	result := 0
 
@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OverlapPolicy decides what happens to overlapping or near-identical reading sessions of the same content from
+// different devices e.g. the book open on two devices or clock drift between devices
+type OverlapPolicy string
+
+const (
+	// OverlapPick keeps the longest of the overlapping sessions
+	OverlapPick OverlapPolicy = "pick"
+
+	// OverlapUnion replaces the overlapping sessions with one session from the first start to the last end
+	OverlapUnion OverlapPolicy = "union"
+
+	// OverlapFlag keeps all the sessions and only reports them
+	OverlapFlag OverlapPolicy = "flag"
+)
+
+// StatsOptions configure how NewStatsWithOptions builds the stats
+type StatsOptions struct {
+	OverlapPolicy OverlapPolicy
+
+	// OverlapTolerance is the gap allowed between sessions from different devices to still count as overlapping
+	OverlapTolerance time.Duration
+}
+
+// DefaultStatsOptions are the options used by NewStats
+var DefaultStatsOptions = StatsOptions{
+	OverlapPolicy:    OverlapPick,
+	OverlapTolerance: 2 * time.Minute,
+}
+
+// StatsOverlap is a report of overlapping sessions and how they were resolved
+type StatsOverlap struct {
+	BookID string `json:"id"`
+	Title  string `json:"title"`
+
+	Reads      []StatsRead   `json:"reads"`
+	Resolution OverlapPolicy `json:"resolution"`
+	Kept       []StatsRead   `json:"kept"`
+}
+
+// ParseOverlapPolicy parses pick, union or flag. Empty is the default policy
+func ParseOverlapPolicy(policy string) (OverlapPolicy, error) {
+	switch OverlapPolicy(strings.ToLower(policy)) {
+	case "":
+		return DefaultStatsOptions.OverlapPolicy, nil
+
+	case OverlapPick:
+		return OverlapPick, nil
+
+	case OverlapUnion:
+		return OverlapUnion, nil
+
+	case OverlapFlag:
+		return OverlapFlag, nil
+	}
+
+	return "", fmt.Errorf("unknown overlap policy %q (pick, union or flag)", policy)
+}
+
+// resolveOverlaps sorts the reads of a book and resolves overlapping reads from different devices using the policy
+func resolveOverlaps(book StatsBook, options StatsOptions) ([]StatsRead, []StatsOverlap) {
+	reads := make([]StatsRead, len(book.Reads))
+	copy(reads, book.Reads)
+
+	sort.SliceStable(reads, func(i, j int) bool {
+		return reads[i].Time < reads[j].Time
+	})
+
+	kept := make([]StatsRead, 0, len(reads))
+	overlaps := make([]StatsOverlap, 0)
+
+	for _, read := range reads {
+		idx := findOverlappingRead(kept, read, options.OverlapTolerance)
+		if idx == -1 {
+			kept = append(kept, read)
+			continue
+		}
+
+		overlap := StatsOverlap{
+			BookID:     book.BookID,
+			Title:      book.Title,
+			Reads:      []StatsRead{kept[idx], read},
+			Resolution: options.OverlapPolicy,
+		}
+
+		switch options.OverlapPolicy {
+		case OverlapUnion:
+			kept[idx] = unionReads(kept[idx], read)
+			overlap.Kept = []StatsRead{kept[idx]}
+
+		case OverlapFlag:
+			kept = append(kept, read)
+			overlap.Kept = overlap.Reads
+
+		default:
+			if read.Duration > kept[idx].Duration {
+				kept[idx] = read
+			}
+
+			overlap.Kept = []StatsRead{kept[idx]}
+		}
+
+		overlaps = append(overlaps, overlap)
+	}
+
+	return kept, overlaps
+}
+
+// findOverlappingRead returns the index of a read from another device that overlaps read, or -1
+func findOverlappingRead(reads []StatsRead, read StatsRead, tolerance time.Duration) int {
+	start, end := read.Span()
+
+	for idx := len(reads) - 1; idx >= 0; idx-- {
+		if reads[idx].Device == read.Device {
+			continue
+		}
+
+		otherStart, otherEnd := reads[idx].Span()
+
+		if !start.After(otherEnd.Add(tolerance)) && !otherStart.After(end.Add(tolerance)) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// unionReads returns one read from the first start to the last end, on the device of the longest read
+func unionReads(a, b StatsRead) StatsRead {
+	aStart, aEnd := a.Span()
+	bStart, bEnd := b.Span()
+
+	start := aStart
+	if bStart.Before(start) {
+		start = bStart
+	}
+
+	end := aEnd
+	if bEnd.After(end) {
+		end = bEnd
+	}
+
+	device := a.Device
+	if b.Duration > a.Duration {
+		device = b.Device
+	}
+
+	return StatsRead{
+		Time:     start.Format(StorageTimeFmt),
+		Duration: int(end.Sub(start).Seconds()),
+		Device:   device,
+	}
+}
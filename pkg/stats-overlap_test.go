@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveOverlaps(t *testing.T) {
+	book := StatsBook{
+		BookID: "books/test-book-a.epub",
+		Title:  "Test Book A",
+		Reads: []StatsRead{
+			{Time: "2024-01-01T20:00:00.000", Duration: 1200, Device: "libra"},
+			{Time: "2024-01-01T20:10:00.000", Duration: 1800, Device: "clara"}, // overlaps libra
+			{Time: "2024-01-01T21:00:00.000", Duration: 600, Device: "libra"},
+			{Time: "2024-01-01T21:11:00.000", Duration: 600, Device: "clara"}, // near-identical after clock drift
+			{Time: "2024-01-02T08:00:00.000", Duration: 600, Device: "clara"},
+			{Time: "2024-01-02T08:05:00.000", Duration: 600, Device: "clara"}, // same device is not an overlap
+		},
+	}
+
+	tests := []struct {
+		name          string
+		policy        OverlapPolicy
+		wantReads     int
+		wantFirstRead StatsRead
+	}{
+		{
+			name:          "pick longest",
+			policy:        OverlapPick,
+			wantReads:     4,
+			wantFirstRead: StatsRead{Time: "2024-01-01T20:10:00.000", Duration: 1800, Device: "clara"},
+		},
+		{
+			name:          "union",
+			policy:        OverlapUnion,
+			wantReads:     4,
+			wantFirstRead: StatsRead{Time: "2024-01-01T20:00:00.000", Duration: 2400, Device: "clara"},
+		},
+		{
+			name:          "flag",
+			policy:        OverlapFlag,
+			wantReads:     6,
+			wantFirstRead: StatsRead{Time: "2024-01-01T20:00:00.000", Duration: 1200, Device: "libra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reads, overlaps := resolveOverlaps(book, StatsOptions{OverlapPolicy: tt.policy, OverlapTolerance: 2 * time.Minute})
+
+			assert.Len(t, reads, tt.wantReads)
+			assert.Equal(t, tt.wantFirstRead, reads[0])
+			assert.Len(t, overlaps, 2)
+			assert.Equal(t, tt.policy, overlaps[0].Resolution)
+		})
+	}
+}
+
+func TestParseOverlapPolicy(t *testing.T) {
+	policy, err := ParseOverlapPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, OverlapPick, policy)
+
+	policy, err = ParseOverlapPolicy("Union")
+	assert.NoError(t, err)
+	assert.Equal(t, OverlapUnion, policy)
+
+	_, err = ParseOverlapPolicy("both")
+	assert.Error(t, err)
+}
@@ -0,0 +1,270 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StatsReadThrough is one read of a book from the first session to the finish. Re-reading a book is a new read-through
+type StatsReadThrough struct {
+	Start          string       `json:"start"`
+	IsFinished     bool         `json:"is_finished"`
+	FinishedTime   string       `json:"finished_time,omitempty"`
+	FinishedSource FinishSource `json:"finished_source,omitempty"`
+
+	Reads []StatsRead `json:"reads"`
+}
+
+// FinishSource is where the finish time of a read-through came from
+type FinishSource string
+
+const (
+	// FinishSourceCorrection is a manual correction
+	FinishSourceCorrection FinishSource = "correction"
+
+	// FinishSourceEvent is a Finish event
+	FinishSourceEvent FinishSource = "event"
+
+	// FinishSourceContent is the LastTimeFinishedReading of the content
+	FinishSourceContent FinishSource = "content"
+
+	// FinishSourceSession is the end of the last reading session (estimated)
+	FinishSourceSession FinishSource = "session"
+
+	// FinishSourceProgress is the 75% progress milestone (estimated)
+	FinishSourceProgress FinishSource = "progress"
+)
+
+// IsEstimated is true when the finish time is a guess rather than recorded by the device
+func (s FinishSource) IsEstimated() bool {
+	return s == FinishSourceSession || s == FinishSourceProgress
+}
+
+// priority of the finish sources, lower is better
+func (s FinishSource) priority() int {
+	switch s {
+	case FinishSourceCorrection:
+		return 0
+	case FinishSourceEvent:
+		return 1
+	case FinishSourceContent:
+		return 2
+	case FinishSourceSession:
+		return 3
+	}
+
+	return 4
+}
+
+// finishMarker is a recorded finish time, from a Finish event or the content LastTimeFinishedReading
+type finishMarker struct {
+	Time   string
+	Source FinishSource
+	Device string
+}
+
+// ReadSeconds is the total duration of the read-through sessions
+func (r StatsReadThrough) ReadSeconds() int {
+	result := 0
+
+	for idx := range r.Reads {
+		result += r.Reads[idx].Duration
+	}
+
+	return result
+}
+
+// newReadThroughs splits the sorted reads by the finish markers. Reads after the last finish are an unfinished
+// read-through. Content that is finished without any finish markers is finished at the end of the last session, or
+// without any sessions at the last 75% progress milestone. Finishes without reading in between are one finish unless
+// readCount e.g. the imported read count says the book was read more often.
+func newReadThroughs(reads []StatsRead, markers []finishMarker, progress75 []string, isFinished bool, readCount int) []StatsReadThrough {
+	sortedMarkers := make([]finishMarker, len(markers))
+	copy(sortedMarkers, markers)
+	sort.Slice(sortedMarkers, func(i, j int) bool {
+		return sortedMarkers[i].Time < sortedMarkers[j].Time
+	})
+
+	result := make([]StatsReadThrough, 0)
+	current := StatsReadThrough{Reads: make([]StatsRead, 0)}
+
+	var lastMarker finishMarker
+
+	finish := func(marker finishMarker) {
+		// Finishes from the same device without reading in between are only re-reads when the read count says so
+		// e.g. imported history without sessions
+		reRead := marker.Device != "" && marker.Device == lastMarker.Device && marker.Source == lastMarker.Source &&
+			len(result) < readCount
+		lastMarker = marker
+
+		if len(current.Reads) == 0 && len(result) > 0 && result[len(result)-1].IsFinished && !reRead {
+			// Another finish without reading in between e.g. finished on two devices, not a re-read. Keep the best source
+			last := &result[len(result)-1]
+			if marker.Source.priority() < last.FinishedSource.priority() {
+				last.FinishedTime = marker.Time
+				last.FinishedSource = marker.Source
+			}
+
+			return
+		}
+
+		if current.Start == "" {
+			current.Start = marker.Time
+		}
+
+		current.IsFinished = true
+		current.FinishedTime = marker.Time
+		current.FinishedSource = marker.Source
+
+		result = append(result, current)
+		current = StatsReadThrough{Reads: make([]StatsRead, 0)}
+	}
+
+	mIdx := 0
+	for _, read := range reads {
+		for mIdx < len(sortedMarkers) && sortedMarkers[mIdx].Time < read.Time {
+			finish(sortedMarkers[mIdx])
+			mIdx++
+		}
+
+		if current.Start == "" {
+			current.Start = read.Time
+		}
+
+		current.Reads = append(current.Reads, read)
+	}
+
+	for ; mIdx < len(sortedMarkers); mIdx++ {
+		finish(sortedMarkers[mIdx])
+	}
+
+	if isFinished && len(result) == 0 {
+		// Finished without any recorded finish time
+		switch {
+		case len(current.Reads) > 0:
+			finish(finishMarker{Time: lastReadEnd(current.Reads), Source: FinishSourceSession})
+
+		case len(progress75) > 0:
+			sortedProgress := make([]string, len(progress75))
+			copy(sortedProgress, progress75)
+			sort.Strings(sortedProgress)
+
+			finish(finishMarker{Time: sortedProgress[len(sortedProgress)-1], Source: FinishSourceProgress})
+		}
+	}
+
+	if len(current.Reads) > 0 {
+		result = append(result, current)
+	}
+
+	return result
+}
+
+// correctFinishMarkers replaces the finish markers and 75% progress of the read-through the corrected finish time falls
+// in with the correction marker. A book read once (at most one read-through with sessions and no imported re-reads) has
+// all of its markers replaced, so a wrong finish before the last sessions does not split it into two read-throughs
+func correctFinishMarkers(reads []StatsRead, markers []finishMarker, progress75 []string, readCount int, finishedTime string) ([]finishMarker, []string) {
+	correction := finishMarker{Time: finishedTime, Source: FinishSourceCorrection}
+
+	readThroughs := newReadThroughs(reads, markers, progress75, true, readCount)
+
+	withReads := 0
+	for idx := range readThroughs {
+		if len(readThroughs[idx].Reads) > 0 {
+			withReads++
+		}
+	}
+
+	if withReads <= 1 && readCount <= 1 {
+		return []finishMarker{correction}, nil
+	}
+
+	// The read-through of the correction is the last one starting before it, its markers are after the finish of the
+	// previous read-through and before the start of the next one
+	rIdx := 0
+	for idx := range readThroughs {
+		if readThroughs[idx].Start <= finishedTime {
+			rIdx = idx
+		}
+	}
+
+	lower, upper := "", ""
+	if rIdx > 0 {
+		lower = readThroughs[rIdx-1].FinishedTime
+	}
+
+	if rIdx+1 < len(readThroughs) {
+		upper = readThroughs[rIdx+1].Start
+	}
+
+	inReadThrough := func(ts string) bool {
+		return (lower == "" || ts > lower) && (upper == "" || ts < upper)
+	}
+
+	resultMarkers := []finishMarker{correction}
+	for _, marker := range markers {
+		if !inReadThrough(marker.Time) {
+			resultMarkers = append(resultMarkers, marker)
+		}
+	}
+
+	resultProgress := make([]string, 0, len(progress75))
+	for _, ts := range progress75 {
+		if !inReadThrough(ts) {
+			resultProgress = append(resultProgress, ts)
+		}
+	}
+
+	return resultMarkers, resultProgress
+}
+
+// lastReadEnd returns the latest end time of the reads
+func lastReadEnd(reads []StatsRead) string {
+	var result time.Time
+
+	for idx := range reads {
+		if _, end := reads[idx].Span(); end.After(result) {
+			result = end
+		}
+	}
+
+	return result.Format(StorageTimeFmt)
+}
+
+// readThroughKey is the key of the read-through in the finished maps. The first read-through is the content ID
+func readThroughKey(cid string, idx int) string {
+	if idx == 0 {
+		return cid
+	}
+
+	return fmt.Sprintf("%s#%d", cid, idx+1)
+}
+
+// ReadThroughBook returns a copy of the book with only the reads and finish of the read-through
+func (b StatsBook) ReadThroughBook(idx int) StatsBook {
+	result := b
+
+	result.ReadThrough = idx + 1
+	result.Reads = b.ReadThroughs[idx].Reads
+	result.IsFinished = b.ReadThroughs[idx].IsFinished
+	result.FinishedTime = b.ReadThroughs[idx].FinishedTime
+	result.FinishedSource = b.ReadThroughs[idx].FinishedSource
+
+	return result
+}
+
+// ReadThroughInMonth returns the last read-through with a read in the month
+func (b StatsBook) ReadThroughInMonth(year, month int) (StatsReadThrough, bool) {
+	for idx := len(b.ReadThroughs) - 1; idx >= 0; idx-- {
+		for _, read := range b.ReadThroughs[idx].Reads {
+			readTime, _ := time.Parse(StorageTimeFmt, read.Time)
+
+			if year == readTime.Year() && month == int(readTime.Month()) {
+				return b.ReadThroughs[idx], true
+			}
+		}
+	}
+
+	return StatsReadThrough{}, false
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReadThroughsFinishSource(t *testing.T) {
+	reads := []StatsRead{
+		{Time: "2023-01-01T20:00:00.000", Duration: 600},
+		{Time: "2023-01-02T20:00:00.000", Duration: 900},
+	}
+
+	tests := []struct {
+		name       string
+		reads      []StatsRead
+		markers    []finishMarker
+		progress75 []string
+		isFinished bool
+		wantTime   string
+		wantSource FinishSource
+	}{
+		{
+			name:       "finish event",
+			reads:      reads,
+			markers:    []finishMarker{{Time: "2023-01-02T20:20:00.000", Source: FinishSourceEvent}},
+			wantTime:   "2023-01-02T20:20:00.000",
+			wantSource: FinishSourceEvent,
+		},
+		{
+			name:  "finish event preferred over content",
+			reads: reads,
+			markers: []finishMarker{
+				{Time: "2023-01-02T20:15:00.000", Source: FinishSourceContent},
+				{Time: "2023-01-02T20:20:00.000", Source: FinishSourceEvent},
+			},
+			wantTime:   "2023-01-02T20:20:00.000",
+			wantSource: FinishSourceEvent,
+		},
+		{
+			name:       "content last finished",
+			reads:      reads,
+			markers:    []finishMarker{{Time: "2023-01-03T08:00:00.000", Source: FinishSourceContent}},
+			wantTime:   "2023-01-03T08:00:00.000",
+			wantSource: FinishSourceContent,
+		},
+		{
+			name:       "last session end",
+			reads:      reads,
+			progress75: []string{"2023-01-02T20:05:00.000"},
+			isFinished: true,
+			wantTime:   "2023-01-02T20:15:00.000",
+			wantSource: FinishSourceSession,
+		},
+		{
+			name:       "75% milestone",
+			progress75: []string{"2022-12-01T20:05:00.000", "2023-01-02T20:05:00.000"},
+			isFinished: true,
+			wantTime:   "2023-01-02T20:05:00.000",
+			wantSource: FinishSourceProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readThroughs := newReadThroughs(tt.reads, tt.markers, tt.progress75, tt.isFinished, 0)
+
+			assert.Len(t, readThroughs, 1)
+			assert.True(t, readThroughs[0].IsFinished)
+			assert.Equal(t, tt.wantTime, readThroughs[0].FinishedTime)
+			assert.Equal(t, tt.wantSource, readThroughs[0].FinishedSource)
+		})
+	}
+
+	t.Run("unfinished", func(t *testing.T) {
+		readThroughs := newReadThroughs(reads, nil, nil, false, 0)
+
+		assert.Len(t, readThroughs, 1)
+		assert.False(t, readThroughs[0].IsFinished)
+		assert.Equal(t, "2023-01-01T20:00:00.000", readThroughs[0].Start)
+	})
+
+	t.Run("finishes without sessions", func(t *testing.T) {
+		markers := []finishMarker{
+			{Time: "2021-03-10T00:00:00.000", Source: FinishSourceEvent, Device: "storygraph"},
+			{Time: "2024-02-09T00:00:00.000", Source: FinishSourceEvent, Device: "storygraph"},
+			{Time: "2024-02-10T08:00:00.000", Source: FinishSourceEvent, Device: "test-device-a"},
+		}
+
+		// Imported re-reads from the same device, the last finish is the same read from another device
+		readThroughs := newReadThroughs(nil, markers, nil, true, 2)
+
+		assert.Len(t, readThroughs, 2)
+		assert.Equal(t, "2021-03-10T00:00:00.000", readThroughs[0].FinishedTime)
+		assert.Equal(t, "2024-02-09T00:00:00.000", readThroughs[1].FinishedTime)
+	})
+
+	t.Run("same device finishes without sessions in between", func(t *testing.T) {
+		markers := []finishMarker{
+			{Time: "2023-01-02T20:20:00.000", Source: FinishSourceEvent, Device: "test-device-a"},
+			{Time: "2023-01-05T08:00:00.000", Source: FinishSourceEvent, Device: "test-device-a"},
+		}
+
+		// Without a read count saying otherwise the second finish is the same read e.g. opened again at the end
+		readThroughs := newReadThroughs(reads, markers, nil, true, 0)
+
+		assert.Len(t, readThroughs, 1)
+		assert.Equal(t, "2023-01-02T20:20:00.000", readThroughs[0].FinishedTime)
+		assert.Len(t, readThroughs[0].Reads, 2)
+	})
+}
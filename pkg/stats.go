@@ -2,6 +2,7 @@
 package pkg
 
 import (
+	"sort"
 	"time"
 )
 
@@ -23,6 +24,9 @@ type Stats struct {
 	Years map[int]YearStats `json:"years"`
 
 	Content map[string]StatsBook `json:"content"`
+
+	// Overlaps are the overlapping sessions from different devices and how they were resolved
+	Overlaps []StatsOverlap `json:"overlaps,omitempty"`
 }
 
 type YearStats struct {
@@ -40,26 +44,98 @@ type MonthStats struct {
 
 // NewStats take a Storage and calculate the full stats
 func NewStats(storage Storage) Stats {
+	return NewStatsWithOptions(storage, DefaultStatsOptions)
+}
+
+// NewStatsWithOptions take a Storage and calculate the full stats using the options
+func NewStatsWithOptions(storage Storage, options StatsOptions) Stats {
 	result := Stats{
-		Years:   make(map[int]YearStats),
-		Content: make(map[string]StatsBook),
+		Years:    make(map[int]YearStats),
+		Content:  make(map[string]StatsBook),
+		Overlaps: make([]StatsOverlap, 0),
+	}
+
+	contents := storage.Contents()
+	identity := NewContentIdentity(contents)
+
+	// corrections are applied after the synced data, per canonical content
+	corrections := canonicalCorrections(identity, storage.Corrections())
+
+	contentByID := make(map[string]StorageContent, len(contents))
+	for _, content := range contents {
+		contentByID[content.ID] = content
 	}
 
-	// Collect all info per content
-	for _, content := range storage.Contents() {
-		book := StatsBook{
-			BookID:     content.ID,
-			Title:      content.Title,
-			Author:     content.Author,
-			URL:        content.URL,
-			Words:      content.Words,
-			IsBook:     content.IsBook,
-			IsFinished: content.IsFinished,
-			Reads:      make([]StatsRead, 0),
-			Bookmarks:  make([]StatsBookmark, 0),
+	// seenEvents dedupes events of aliases e.g. the same session synced under two paths
+	seenEvents := make(map[string]bool)
+
+	// seenBookmarks dedupes bookmarks of aliases by ID and modified time like UndoSync matches them
+	seenBookmarks := make(map[string]bool)
+
+	// finishes are the recorded finish times per content, used to split the reads into read-throughs
+	finishes := make(map[string][]finishMarker)
+
+	// progress75 are the 75% milestone times per content, the last resort finish time
+	progress75 := make(map[string][]string)
+
+	// Collect all info per content, aliases of the same book are collected in the canonical content
+	for _, content := range contents {
+		cid := identity.Canonical(content.ID)
+
+		correction := corrections[cid]
+		if correction.Hidden {
+			continue
+		}
+
+		book, exists := result.Content[cid]
+		if !exists {
+			canonical := contentByID[cid]
+
+			book = StatsBook{
+				BookID:     cid,
+				Title:      canonical.Title,
+				Author:     canonical.Author,
+				URL:        canonical.URL,
+				ISBN:       canonical.ISBN,
+				Words:      canonical.Words,
+				IsBook:     canonical.IsBook,
+				IsFinished: canonical.IsFinished,
+				Reads:      make([]StatsRead, 0),
+				Bookmarks:  make([]StatsBookmark, 0),
+			}
+
+			if aliases := identity.Aliases(cid); len(aliases) > 1 {
+				book.Aliases = aliases
+			}
+
+			if correction.Title != "" {
+				book.Title = correction.Title
+			}
+
+			if correction.Author != "" {
+				book.Author = correction.Author
+			}
+		}
+
+		book.IsFinished = book.IsFinished || content.IsFinished
+		book.ReadCount = max(book.ReadCount, content.ReadCount)
+
+		if book.Rating == 0 {
+			book.Rating = content.Rating
+		}
+
+		if book.Words == 0 {
+			book.Words = content.Words
 		}
 
 		for _, event := range storage.Events(content.ID) {
+			eventKey := cid + "|" + event.EventName + "|" + event.Time + "|" + event.Device
+			if seenEvents[eventKey] {
+				continue
+			}
+
+			seenEvents[eventKey] = true
+
 			eventTime, err := time.Parse(StorageTimeFmt, event.Time)
 			if err != nil {
 				panic(err)
@@ -68,47 +144,39 @@ func NewStats(storage Storage) Stats {
 			switch event.EventName {
 			case FinishEvent.String():
 				book.IsFinished = true
-				book.FinishedTime = event.Time
+				finishes[cid] = append(finishes[cid], finishMarker{Time: event.Time, Source: FinishSourceEvent, Device: event.Device})
+
+			case LastFinishedEvent.String():
+				book.IsFinished = true
+				finishes[cid] = append(finishes[cid], finishMarker{Time: event.Time, Source: FinishSourceContent, Device: event.Device})
+
+			case Progress75Event.String():
+				progress75[cid] = append(progress75[cid], event.Time)
 
 			case ReadEvent.String():
+				if correction.isDeletedSession(event.Time) {
+					continue
+				}
+
 				book.Reads = append(book.Reads, StatsRead{
 					Time:     event.Time,
 					Duration: event.Duration,
+					Device:   event.Device,
 				})
-
-				if book.IsFinished && book.FinishedTime == "" {
-					book.FinishedTime = event.Time // TODO!
-				}
 			}
 
-			if _, exists := result.Years[eventTime.Year()]; !exists {
-				result.Years[eventTime.Year()] = YearStats{
-					Months: make(map[int]MonthStats),
-					Weeks:  make(map[int]MonthStats),
-				}
-
-				for idx := 1; idx <= 12; idx++ {
-					result.Years[eventTime.Year()].Months[idx] = MonthStats{
-						FinishedBooks:    make(map[string]*StatsBook),
-						FinishedArticles: make(map[string]*StatsBook),
-						Books:            make(map[string]*StatsBook),
-						Articles:         make(map[string]*StatsBook),
-					}
-				}
-
-				for idx := 1; idx <= 53; idx++ {
-					result.Years[eventTime.Year()].Weeks[idx] = MonthStats{
-						FinishedBooks:    make(map[string]*StatsBook),
-						FinishedArticles: make(map[string]*StatsBook),
-						Books:            make(map[string]*StatsBook),
-						Articles:         make(map[string]*StatsBook),
-					}
-				}
-			}
+			result.ensureYear(eventTime.Year())
 		}
 
 		bookmarks := storage.Bookmarks(content.ID)
 		for idx := range bookmarks {
+			bookmarkKey := cid + "|" + bookmarks[idx].ID + "|" + bookmarks[idx].Modified
+			if seenBookmarks[bookmarkKey] {
+				continue
+			}
+
+			seenBookmarks[bookmarkKey] = true
+
 			book.Bookmarks = append(book.Bookmarks, StatsBookmark{
 				ID:          bookmarks[idx].ID,
 				Index:       bookmarks[idx].Index,
@@ -122,15 +190,61 @@ func NewStats(storage Storage) Stats {
 			})
 		}
 
-		result.Content[content.ID] = book
+		result.Content[cid] = book
+	}
+
+	// Resolve overlapping sessions from different devices and split the reads into read-throughs
+	for cid := range result.Content {
+		book := result.Content[cid]
+
+		var overlaps []StatsOverlap
+		book.Reads, overlaps = resolveOverlaps(book, options)
+
+		if correction := corrections[cid]; correction.Finished != nil {
+			book.IsFinished = *correction.Finished
+
+			if !book.IsFinished {
+				finishes[cid] = nil
+			} else if correction.FinishedTime != "" {
+				finishes[cid], progress75[cid] = correctFinishMarkers(book.Reads, finishes[cid], progress75[cid], book.ReadCount,
+					correction.FinishedTime)
+			}
+		}
+
+		book.ReadThroughs = newReadThroughs(book.Reads, finishes[cid], progress75[cid], book.IsFinished, book.ReadCount)
+		finishedCount := 0
+		for idx := range book.ReadThroughs {
+			if book.ReadThroughs[idx].IsFinished {
+				book.FinishedTime = book.ReadThroughs[idx].FinishedTime
+				book.FinishedSource = book.ReadThroughs[idx].FinishedSource
+				finishedCount++
+			}
+		}
+
+		book.ReadCount = max(book.ReadCount, finishedCount)
+
+		result.Content[cid] = book
+		result.Overlaps = append(result.Overlaps, overlaps...)
 	}
 
+	sort.Slice(result.Overlaps, func(i, j int) bool {
+		return result.Overlaps[i].Reads[0].Time < result.Overlaps[j].Reads[0].Time
+	})
+
 	// Content to Months
 	for cid := range result.Content {
 		book := result.Content[cid]
 
-		if result.Content[cid].IsFinished && result.Content[cid].FinishedTime != "" {
-			finishedTime, err := time.Parse(StorageTimeFmt, result.Content[cid].FinishedTime)
+		// Each finished read-through is a finished entry, the first read-through uses the content ID
+		for rIdx := range book.ReadThroughs {
+			if !book.ReadThroughs[rIdx].IsFinished || book.ReadThroughs[rIdx].FinishedTime == "" {
+				continue
+			}
+
+			readThrough := book.ReadThroughBook(rIdx)
+			key := readThroughKey(cid, rIdx)
+
+			finishedTime, err := time.Parse(StorageTimeFmt, readThrough.FinishedTime)
 			if err != nil {
 				panic(err)
 			}
@@ -139,21 +253,24 @@ func NewStats(storage Storage) Stats {
 			finishedMonth := int(finishedTime.Month())
 			finishedYearWeek, finishedWeek := finishedTime.ISOWeek() // Note: readYearWeek may differ from readYear e.g. the timestamp is 2024-12-30 but that is in week 1 of 2025
 
+			result.ensureYear(finishedYear)
+			result.ensureYear(finishedYearWeek)
+
 			if result.Content[cid].IsBook {
-				if _, exists := result.Years[finishedYear].Months[finishedMonth].FinishedBooks[cid]; !exists {
-					result.Years[finishedYear].Months[finishedMonth].FinishedBooks[cid] = &book
+				if _, exists := result.Years[finishedYear].Months[finishedMonth].FinishedBooks[key]; !exists {
+					result.Years[finishedYear].Months[finishedMonth].FinishedBooks[key] = &readThrough
 				}
 
-				if _, exists := result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedBooks[cid]; !exists {
-					result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedBooks[cid] = &book
+				if _, exists := result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedBooks[key]; !exists {
+					result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedBooks[key] = &readThrough
 				}
 			} else {
-				if _, exists := result.Years[finishedYear].Months[finishedMonth].FinishedArticles[cid]; !exists {
-					result.Years[finishedYear].Months[finishedMonth].FinishedArticles[cid] = &book
+				if _, exists := result.Years[finishedYear].Months[finishedMonth].FinishedArticles[key]; !exists {
+					result.Years[finishedYear].Months[finishedMonth].FinishedArticles[key] = &readThrough
 				}
 
-				if _, exists := result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedArticles[cid]; !exists {
-					result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedArticles[cid] = &book
+				if _, exists := result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedArticles[key]; !exists {
+					result.Years[finishedYearWeek].Weeks[finishedWeek].FinishedArticles[key] = &readThrough
 				}
 			}
 		}
@@ -168,6 +285,9 @@ func NewStats(storage Storage) Stats {
 			readMonth := int(readTime.Month())
 			readYearWeek, readWeek := readTime.ISOWeek() // Note: readYearWeek may differ from readYear e.g. the timestamp is 2024-12-30 but that is in week 1 of 2025
 
+			result.ensureYear(readYear)
+			result.ensureYear(readYearWeek)
+
 			if result.Content[cid].IsBook {
 				if _, exists := result.Years[readYear].Months[readMonth].Books[cid]; !exists {
 					result.Years[readYear].Months[readMonth].Books[cid] = &book
@@ -191,6 +311,36 @@ func NewStats(storage Storage) Stats {
 	return result
 }
 
+// ensureYear creates the empty months and weeks of the year
+func (s Stats) ensureYear(year int) {
+	if _, exists := s.Years[year]; exists {
+		return
+	}
+
+	s.Years[year] = YearStats{
+		Months: make(map[int]MonthStats),
+		Weeks:  make(map[int]MonthStats),
+	}
+
+	for idx := 1; idx <= 12; idx++ {
+		s.Years[year].Months[idx] = MonthStats{
+			FinishedBooks:    make(map[string]*StatsBook),
+			FinishedArticles: make(map[string]*StatsBook),
+			Books:            make(map[string]*StatsBook),
+			Articles:         make(map[string]*StatsBook),
+		}
+	}
+
+	for idx := 1; idx <= 53; idx++ {
+		s.Years[year].Weeks[idx] = MonthStats{
+			FinishedBooks:    make(map[string]*StatsBook),
+			FinishedArticles: make(map[string]*StatsBook),
+			Books:            make(map[string]*StatsBook),
+			Articles:         make(map[string]*StatsBook),
+		}
+	}
+}
+
 func (s Stats) BooksFinishedYear(year int) []*StatsBook {
 	result := make([]*StatsBook, 0)
 
@@ -1,7 +1,9 @@
 package pkg
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -52,6 +54,7 @@ func TestNewStatsForYear(t *testing.T) {
 
 		testStorage := NewMockStorage(ctrl)
 		testStorage.EXPECT().Contents().Return(testContents)
+		testStorage.EXPECT().Corrections().Return(map[string]StorageCorrection{})
 		testStorage.EXPECT().Events(testBookAID).Return([]StorageEvents{
 			{EventName: "Read", Time: "2020-02-01T01:02:03.000", Duration: 100, Device: testDeviceAID},
 			{EventName: "Read", Time: "2020-02-01T02:02:03.000", Duration: 200, Device: testDeviceAID},
@@ -81,3 +84,44 @@ func TestNewStatsForYear(t *testing.T) {
 		assert.Len(t, finYear[0].Reads, 3)
 	})
 }
+
+func TestNewStatsReadThroughs(t *testing.T) {
+	const (
+		testDeviceAID = "test-device-a"
+		testBookAID   = "books/test-book-a.epub"
+	)
+
+	storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	storage.AddContent(testBookAID, "Test Book A", "AAA", "", "", 123, true, true, 100)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2022, 3, 1, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2022, 3, 2, 20, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2022, 3, 2, 20, 10, 0, 0, time.UTC), 0)
+	storage.AddEvent(testBookAID, "test-device-b", FinishEvent.String(), time.Date(2022, 3, 3, 8, 0, 0, 0, time.UTC), 0)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC), 900)
+	storage.AddEvent(testBookAID, testDeviceAID, FinishEvent.String(), time.Date(2024, 2, 1, 20, 0, 0, 0, time.UTC), 0)
+	storage.AddEvent(testBookAID, testDeviceAID, ReadEvent.String(), time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC), 300)
+
+	stats := NewStats(storage)
+
+	book := stats.Content[testBookAID]
+	assert.Len(t, book.ReadThroughs, 3)
+	assert.False(t, book.ReadThroughs[2].IsFinished)
+	assert.Equal(t, "2024-02-01T20:00:00.000", book.FinishedTime)
+
+	finished2022 := stats.BooksFinishedYear(2022)
+	assert.Len(t, finished2022, 1)
+	assert.Equal(t, 1200, finished2022[0].ReadSeconds())
+	assert.Equal(t, 1, finished2022[0].ReadThrough)
+
+	finished2024 := stats.BooksFinishedYear(2024)
+	assert.Len(t, finished2024, 1)
+	assert.Equal(t, 900, finished2024[0].ReadSeconds())
+	assert.Equal(t, 2, finished2024[0].ReadThrough)
+	assert.Equal(t, "2024-01-05T20:00:00.000", finished2024[0].FirstReadTime())
+
+	readThrough, ok := book.ReadThroughInMonth(2024, 6)
+	assert.True(t, ok)
+	assert.False(t, readThrough.IsFinished)
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// cowStorage is a copy-on-write view of a Storage. Reads go to the base storage until the first write,
+// then the base is copied and all reads and writes use the copy. Save never writes anything.
+type cowStorage struct {
+	base Storage
+	copy *JSONStorage
+}
+
+// NewCopyOnWriteStorage returns a Storage view of base that is never saved, changes are only visible through the view
+func NewCopyOnWriteStorage(base Storage) Storage {
+	return &cowStorage{base: base}
+}
+
+func (s *cowStorage) reader() Storage {
+	if s.copy != nil {
+		return s.copy
+	}
+
+	return s.base
+}
+
+func (s *cowStorage) writer() *JSONStorage {
+	if s.copy == nil {
+		storageBytes, err := json.Marshal(s.base)
+		if err != nil {
+			panic(err)
+		}
+
+		s.copy = newJSONStorage("")
+		if err := json.Unmarshal(storageBytes, s.copy); err != nil {
+			panic(err)
+		}
+	}
+
+	return s.copy
+}
+
+func (s *cowStorage) Save() error {
+	return nil
+}
+
+func (s *cowStorage) AddContent(fn, title, author, url, isbn string, words int, book, finished bool, percent int) {
+	s.writer().AddContent(fn, title, author, url, isbn, words, book, finished, percent)
+}
+
+func (s *cowStorage) SetContentDetails(fn string, rating, readCount int) {
+	s.writer().SetContentDetails(fn, rating, readCount)
+}
+
+func (s *cowStorage) AddDevice(device, model string) {
+	s.writer().AddDevice(device, model)
+}
+
+func (s *cowStorage) AddEvent(fn, device, name string, t time.Time, duration int) {
+	s.writer().AddEvent(fn, device, name, t, duration)
+}
+
+func (s *cowStorage) RemoveEvent(fn, device, name string, t time.Time) {
+	s.writer().RemoveEvent(fn, device, name, t)
+}
+
+func (s *cowStorage) AddShelf(ID, name, internalName, shelfType string, isDeleted bool) {
+	s.writer().AddShelf(ID, name, internalName, shelfType, isDeleted)
+}
+
+func (s *cowStorage) AddShelfContent(shelfName, fn string, isDeleted bool) {
+	s.writer().AddShelfContent(shelfName, fn, isDeleted)
+}
+
+func (s *cowStorage) Devices() []StorageDevice {
+	return s.reader().Devices()
+}
+
+func (s *cowStorage) Contents() []StorageContent {
+	return s.reader().Contents()
+}
+
+func (s *cowStorage) Events(cID string) []StorageEvents {
+	return s.reader().Events(cID)
+}
+
+func (s *cowStorage) Bookmarks(cID string) []StorageBookmark {
+	return s.reader().Bookmarks(cID)
+}
+
+func (s *cowStorage) Shelfs() []StorageShelf {
+	return s.reader().Shelfs()
+}
+
+func (s *cowStorage) ShelfContents(shelfName string) []StorageShelfContent {
+	return s.reader().ShelfContents(shelfName)
+}
+
+func (s *cowStorage) AddBookmark(bID, vID, cID, typeStr, path string, index, startOffset, endOffset int, text, annotation string, created, modified time.Time) {
+	s.writer().AddBookmark(bID, vID, cID, typeStr, path, index, startOffset, endOffset, text, annotation, created, modified)
+}
+
+func (s *cowStorage) StartSync(device, database, hash string, t time.Time) {
+	s.writer().StartSync(device, database, hash, t)
+}
+
+func (s *cowStorage) FinishSync() StorageSync {
+	return s.writer().FinishSync()
+}
+
+func (s *cowStorage) Syncs() []StorageSync {
+	return s.reader().Syncs()
+}
+
+func (s *cowStorage) UndoSync(ID int) (StorageSync, error) {
+	return s.writer().UndoSync(ID)
+}
+
+func (s *cowStorage) SetCorrection(cID string, correction StorageCorrection) {
+	s.writer().SetCorrection(cID, correction)
+}
+
+func (s *cowStorage) Corrections() map[string]StorageCorrection {
+	return s.reader().Corrections()
+}
@@ -3,6 +3,8 @@ package pkg
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"time"
 )
@@ -10,13 +12,16 @@ import (
 type Storage interface {
 	Save() error
 
-	AddContent(fn, title, author, url string, words int, book, finished bool, percent int)
+	AddContent(fn, title, author, url, isbn string, words int, book, finished bool, percent int)
+	SetContentDetails(fn string, rating, readCount int)
 	AddDevice(device, model string)
 	AddEvent(fn, device, name string, t time.Time, duration int)
+	RemoveEvent(fn, device, name string, t time.Time)
 
 	AddShelf(ID, name, internalName, shelfType string, isDeleted bool)
 	AddShelfContent(shelfName, fn string, isDeleted bool)
 
+	Devices() []StorageDevice
 	Contents() []StorageContent
 	Events(cID string) []StorageEvents
 	Bookmarks(cID string) []StorageBookmark
@@ -25,6 +30,14 @@ type Storage interface {
 	ShelfContents(shelfName string) []StorageShelfContent
 
 	AddBookmark(bID, vID, cID, typeStr, path string, index, startOffset, endOffset int, text, annotation string, created, modified time.Time)
+
+	StartSync(device, database, hash string, t time.Time)
+	FinishSync() StorageSync
+	Syncs() []StorageSync
+	UndoSync(ID int) (StorageSync, error)
+
+	SetCorrection(cID string, correction StorageCorrection)
+	Corrections() map[string]StorageCorrection
 }
 
 type JSONStorage struct {
@@ -32,12 +45,21 @@ type JSONStorage struct {
 	ContentMap map[string]StorageContent  `json:"contents"`
 	EventMap   map[string][]StorageEvents `json:"events"`
 
-	// Extra data that may be interesting. TODO need to think about relating content across devices e.g. books with different CIDs, fine for stats but not for shelve content
+	// Extra data that may be interesting. Content across devices e.g. books with different CIDs is related by ContentIdentity
 	Shelf        map[string]StorageShelf          `json:"shelf"`
 	ShelfContent map[string][]StorageShelfContent `json:"shelf_content"`
 	Bookmark     map[string][]StorageBookmark     `json:"bookmark"`
 
+	// Journal of every Sync run, used to show history and undo a sync
+	Journal []StorageSync `json:"journal"`
+
+	// Manual corrections of the synced data, applied by NewStats. Sync never changes them
+	Correction map[string]StorageCorrection `json:"corrections,omitempty"`
+
 	fn string
+
+	// currentSync is the journal entry being recorded between StartSync and FinishSync
+	currentSync *StorageSync
 }
 
 type StorageDevice struct {
@@ -50,11 +72,19 @@ type StorageContent struct {
 	Title  string `json:"title"`
 	Author string `json:"author"`
 	URL    string `json:"url"`
+	ISBN   string `json:"isbn,omitempty"`
 
 	Words int `json:"words"`
 
 	IsBook     bool `json:"book"`
 	IsFinished bool `json:"article_is_finished"`
+
+	// Rating (1-5) and ReadCount are only known from imports e.g. Goodreads
+	Rating    int `json:"rating,omitempty"`
+	ReadCount int `json:"read_count,omitempty"`
+
+	// Added is when the content was first stored, empty for content stored before it was recorded
+	Added string `json:"added,omitempty"`
 }
 
 type StorageEvents struct {
@@ -93,20 +123,89 @@ type StorageBookmark struct {
 	Type        string `json:"type,omitempty"`
 }
 
+// StorageSync is a journal entry of what a single Sync added or changed
+type StorageSync struct {
+	ID       int    `json:"id"`
+	Device   string `json:"device"`
+	Database string `json:"database"`
+	Hash     string `json:"hash"`
+	Time     string `json:"time"`
+
+	Contents  []StorageSyncContent  `json:"contents,omitempty"`
+	Events    []StorageSyncEvent    `json:"events,omitempty"`
+	Bookmarks []StorageSyncBookmark `json:"bookmarks,omitempty"`
+
+	// RemovedEvents are the events the sync removed e.g. a finish replaced by a re-import, restored by undo
+	RemovedEvents []StorageSyncRemovedEvent `json:"removed_events,omitempty"`
+
+	Devices       []StorageSyncDevice       `json:"devices,omitempty"`
+	ShelfContents []StorageSyncShelfContent `json:"shelf_contents,omitempty"`
+}
+
+type StorageSyncDevice struct {
+	Device string `json:"device"`
+
+	// Previous is the device before the sync changed its model, nil if the sync added the device
+	Previous *StorageDevice `json:"previous,omitempty"`
+}
+
+type StorageSyncShelfContent struct {
+	ShelfID   string `json:"shelf_id"`
+	ContentID string `json:"content_id"`
+}
+
+type StorageSyncContent struct {
+	ID string `json:"id"`
+
+	// Previous is the content before the sync changed it, nil if the sync added the content
+	Previous *StorageContent `json:"previous,omitempty"`
+}
+
+type StorageSyncEvent struct {
+	ContentID string `json:"content_id"`
+	EventName string `json:"event"`
+	Time      string `json:"time"`
+}
+
+type StorageSyncRemovedEvent struct {
+	ContentID string        `json:"content_id"`
+	Event     StorageEvents `json:"event"`
+}
+
+type StorageSyncBookmark struct {
+	ContentID string `json:"content_id"`
+	ID        string `json:"id"`
+	Modified  string `json:"modified"`
+}
+
+// StorageCorrection is a manual fix of synced content e.g. a wrong author or a bogus session
+type StorageCorrection struct {
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+
+	// Finished forces the finished state, nil keeps the synced state. FinishedTime is optional
+	Finished     *bool  `json:"finished,omitempty"`
+	FinishedTime string `json:"finished_time,omitempty"`
+
+	// DeletedSessions are the times of the Read events to ignore
+	DeletedSessions []string `json:"deleted_sessions,omitempty"`
+
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// IsZero is true when the correction does not change anything
+func (c StorageCorrection) IsZero() bool {
+	return c.Title == "" && c.Author == "" && c.Finished == nil && c.FinishedTime == "" && len(c.DeletedSessions) == 0 && !c.Hidden
+}
+
 const (
 	StorageTimeFmt = "2006-01-02T15:04:05.000"
 )
 
+var ErrSyncNotFound = errors.New("sync not found")
+
 func OpenStorageOrCreate(fn string) (Storage, error) {
-	storage := JSONStorage{
-		DeviceMap:    map[string]StorageDevice{},
-		ContentMap:   map[string]StorageContent{},
-		EventMap:     map[string][]StorageEvents{},
-		Shelf:        map[string]StorageShelf{},
-		ShelfContent: map[string][]StorageShelfContent{},
-		Bookmark:     map[string][]StorageBookmark{},
-		fn:           fn,
-	}
+	storage := newJSONStorage(fn)
 
 	if _, err := os.Stat(fn); err == nil {
 		storageBytes, err := os.ReadFile(fn)
@@ -114,13 +213,30 @@ func OpenStorageOrCreate(fn string) (Storage, error) {
 			return nil, err
 		}
 
-		err = json.Unmarshal(storageBytes, &storage)
+		err = json.Unmarshal(storageBytes, storage)
 		if err != nil {
 			return nil, err
 		}
+
+		if storage.Correction == nil {
+			storage.Correction = map[string]StorageCorrection{}
+		}
 	}
 
-	return &storage, nil
+	return storage, nil
+}
+
+func newJSONStorage(fn string) *JSONStorage {
+	return &JSONStorage{
+		DeviceMap:    map[string]StorageDevice{},
+		ContentMap:   map[string]StorageContent{},
+		EventMap:     map[string][]StorageEvents{},
+		Shelf:        map[string]StorageShelf{},
+		ShelfContent: map[string][]StorageShelfContent{},
+		Bookmark:     map[string][]StorageBookmark{},
+		Correction:   map[string]StorageCorrection{},
+		fn:           fn,
+	}
 }
 
 func (s *JSONStorage) Save() error {
@@ -132,10 +248,12 @@ func (s *JSONStorage) Save() error {
 	return os.WriteFile(s.fn, storageBytes, 0o644)
 }
 
-func (s *JSONStorage) AddContent(fn, title, author, url string, words int, book, finished bool, percent int) {
-	previouslyFinished := false
-	if _, ok := s.ContentMap[fn]; ok {
-		previouslyFinished = s.ContentMap[fn].IsFinished
+func (s *JSONStorage) AddContent(fn, title, author, url, isbn string, words int, book, finished bool, percent int) {
+	previous, exists := s.ContentMap[fn]
+
+	if isbn == "" {
+		// Keep a known ISBN e.g. from another device
+		isbn = previous.ISBN
 	}
 
 	if !book && percent == 100 {
@@ -143,22 +261,67 @@ func (s *JSONStorage) AddContent(fn, title, author, url string, words int, book,
 		finished = true
 	}
 
-	s.ContentMap[fn] = StorageContent{
+	content := StorageContent{
 		ID:         fn,
 		Title:      title,
 		Author:     author,
 		Words:      words,
 		URL:        url,
+		ISBN:       isbn,
 		IsBook:     book,
-		IsFinished: finished || previouslyFinished, // Content cannot go from 'finished' to unfinished (e.g. duplicate content across multiple devices)
+		IsFinished: finished || previous.IsFinished, // Content cannot go from 'finished' to unfinished (e.g. duplicate content across multiple devices)
+		Rating:     previous.Rating,
+		ReadCount:  previous.ReadCount,
+		Added:      previous.Added,
+	}
+
+	if !exists {
+		content.Added = time.Now().Format(StorageTimeFmt)
+	}
+
+	if s.currentSync != nil && (!exists || previous != content) {
+		s.journalContent(fn, previous, exists)
+	}
+
+	s.ContentMap[fn] = content
+}
+
+// SetContentDetails sets the rating and read count of existing content, zero keeps the current value
+func (s *JSONStorage) SetContentDetails(fn string, rating, readCount int) {
+	previous, exists := s.ContentMap[fn]
+	if !exists {
+		return
+	}
+
+	content := previous
+	if rating != 0 {
+		content.Rating = rating
+	}
+
+	if readCount != 0 {
+		content.ReadCount = readCount
+	}
+
+	if s.currentSync != nil && previous != content {
+		s.journalContent(fn, previous, exists)
 	}
+
+	s.ContentMap[fn] = content
 }
 
 func (s *JSONStorage) AddDevice(device, model string) {
-	s.DeviceMap[device] = StorageDevice{
+	previous, exists := s.DeviceMap[device]
+
+	current := StorageDevice{
 		Device: device,
 		Model:  model,
 	}
+
+	if s.currentSync != nil && (!exists || previous != current) {
+		s.journalDevice(device, previous, exists)
+	}
+
+	s.DeviceMap[device] = current
 }
 
 func (s *JSONStorage) AddEvent(fn, device, name string, t time.Time, duration int) {
@@ -178,9 +341,58 @@ func (s *JSONStorage) AddEvent(fn, device, name string, t time.Time, duration in
 			Duration:  duration,
 			Device:    device,
 		})
+
+		if s.currentSync != nil {
+			s.currentSync.Events = append(s.currentSync.Events, StorageSyncEvent{
+				ContentID: fn,
+				EventName: name,
+				Time:      timeStr,
+			})
+		}
 	}
 }
 
+// RemoveEvent removes the event of the device with the name and time, if it exists
+func (s *JSONStorage) RemoveEvent(fn, device, name string, t time.Time) {
+	timeStr := t.Format(StorageTimeFmt)
+
+	events := make([]StorageEvents, 0, len(s.EventMap[fn]))
+	for _, event := range s.EventMap[fn] {
+		if event.Device == device && event.EventName == name && event.Time == timeStr {
+			if s.currentSync != nil {
+				s.currentSync.RemovedEvents = append(s.currentSync.RemovedEvents, StorageSyncRemovedEvent{
+					ContentID: fn,
+					Event:     event,
+				})
+			}
+
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) == len(s.EventMap[fn]) {
+		return
+	}
+
+	if len(events) == 0 {
+		delete(s.EventMap, fn)
+	} else {
+		s.EventMap[fn] = events
+	}
+}
+
+func (s *JSONStorage) Devices() []StorageDevice {
+	result := make([]StorageDevice, 0, len(s.DeviceMap))
+
+	for _, device := range s.DeviceMap {
+		result = append(result, device)
+	}
+
+	return result
+}
+
 func (s *JSONStorage) Contents() []StorageContent {
 	result := make([]StorageContent, len(s.ContentMap))
 	idx := 0
@@ -224,7 +436,7 @@ func (s *JSONStorage) AddShelfContent(shelfName, fn string, isDeleted bool) {
 		s.ShelfContent = map[string][]StorageShelfContent{} // TODO/FIXME ! panic without but is initialised in Open function ??
 	}
 
-	if _, exists := s.Shelf[shelfName]; !exists {
+	if _, exists := s.ShelfContent[shelfName]; !exists {
 		s.ShelfContent[shelfName] = make([]StorageShelfContent, 0)
 	}
 
@@ -244,11 +456,18 @@ func (s *JSONStorage) AddShelfContent(shelfName, fn string, isDeleted bool) {
 			ContentID: fn,
 			IsDeleted: isDeleted,
 		})
+
+		if s.currentSync != nil {
+			s.currentSync.ShelfContents = append(s.currentSync.ShelfContents, StorageSyncShelfContent{
+				ShelfID:   shelfName,
+				ContentID: fn,
+			})
+		}
 	}
 }
 
 func (s *JSONStorage) Shelfs() []StorageShelf {
-	result := make([]StorageShelf, len(s.Shelf))
+	result := make([]StorageShelf, 0, len(s.Shelf))
 
 	for idx := range s.Shelf {
 		result = append(result, s.Shelf[idx])
@@ -258,7 +477,7 @@ func (s *JSONStorage) Shelfs() []StorageShelf {
 }
 
 func (s *JSONStorage) ShelfContents(shelfName string) []StorageShelfContent {
-	result := make([]StorageShelfContent, len(s.ShelfContent[shelfName]))
+	result := make([]StorageShelfContent, 0, len(s.ShelfContent[shelfName]))
 	result = append(result, s.ShelfContent[shelfName]...)
 
 	return result
@@ -290,6 +509,14 @@ func (s *JSONStorage) AddBookmark(bID, vID, cID, typeStr, path string, index, st
 			Modified:    modifiedStr,
 			Type:        typeStr,
 		})
+
+		if s.currentSync != nil {
+			s.currentSync.Bookmarks = append(s.currentSync.Bookmarks, StorageSyncBookmark{
+				ContentID: cID,
+				ID:        bID,
+				Modified:  modifiedStr,
+			})
+		}
 	}
 }
 
@@ -299,3 +526,285 @@ func (s *JSONStorage) Bookmarks(cID string) []StorageBookmark {
 
 	return result
 }
+
+// StartSync opens a new journal entry. Devices, content, events, bookmarks and shelf entries added until FinishSync are
+// recorded in it
+func (s *JSONStorage) StartSync(device, database, hash string, t time.Time) {
+	nextID := 1
+	for idx := range s.Journal {
+		if s.Journal[idx].ID >= nextID {
+			nextID = s.Journal[idx].ID + 1
+		}
+	}
+
+	s.currentSync = &StorageSync{
+		ID:       nextID,
+		Device:   device,
+		Database: database,
+		Hash:     hash,
+		Time:     t.Format(StorageTimeFmt),
+	}
+}
+
+// FinishSync closes the journal entry opened by StartSync, adds it to the journal and returns it
+func (s *JSONStorage) FinishSync() StorageSync {
+	if s.currentSync == nil {
+		return StorageSync{}
+	}
+
+	result := *s.currentSync
+	s.Journal = append(s.Journal, result)
+	s.currentSync = nil
+
+	return result
+}
+
+func (s *JSONStorage) Syncs() []StorageSync {
+	result := make([]StorageSync, 0, len(s.Journal))
+	result = append(result, s.Journal...)
+
+	return result
+}
+
+// UndoSync removes everything the sync with ID added and restores content it changed and events it removed. Content
+// and devices the sync added are kept while events, bookmarks or shelf entries it did not add still use them e.g. a
+// logged session. The entry is removed from the journal
+func (s *JSONStorage) UndoSync(ID int) (StorageSync, error) {
+	jIdx := -1
+	for idx := range s.Journal {
+		if s.Journal[idx].ID == ID {
+			jIdx = idx
+			break
+		}
+	}
+
+	if jIdx == -1 {
+		return StorageSync{}, fmt.Errorf("%w: %d", ErrSyncNotFound, ID)
+	}
+
+	entry := s.Journal[jIdx]
+
+	for _, event := range entry.Events {
+		events := make([]StorageEvents, 0, len(s.EventMap[event.ContentID]))
+		for eIdx := range s.EventMap[event.ContentID] {
+			if s.EventMap[event.ContentID][eIdx].EventName == event.EventName && s.EventMap[event.ContentID][eIdx].Time == event.Time {
+				continue
+			}
+
+			events = append(events, s.EventMap[event.ContentID][eIdx])
+		}
+
+		if len(events) == 0 {
+			delete(s.EventMap, event.ContentID)
+		} else {
+			s.EventMap[event.ContentID] = events
+		}
+	}
+
+	for _, removed := range entry.RemovedEvents {
+		found := false
+		for _, event := range s.EventMap[removed.ContentID] {
+			if event.EventName == removed.Event.EventName && event.Time == removed.Event.Time {
+				found = true
+			}
+		}
+
+		if !found {
+			s.EventMap[removed.ContentID] = append(s.EventMap[removed.ContentID], removed.Event)
+		}
+	}
+
+	for _, bookmark := range entry.Bookmarks {
+		bookmarks := make([]StorageBookmark, 0, len(s.Bookmark[bookmark.ContentID]))
+		for bIdx := range s.Bookmark[bookmark.ContentID] {
+			if s.Bookmark[bookmark.ContentID][bIdx].ID == bookmark.ID && s.Bookmark[bookmark.ContentID][bIdx].Modified == bookmark.Modified {
+				continue
+			}
+
+			bookmarks = append(bookmarks, s.Bookmark[bookmark.ContentID][bIdx])
+		}
+
+		if len(bookmarks) == 0 {
+			delete(s.Bookmark, bookmark.ContentID)
+		} else {
+			s.Bookmark[bookmark.ContentID] = bookmarks
+		}
+	}
+
+	for _, shelfContent := range entry.ShelfContents {
+		shelfContents := make([]StorageShelfContent, 0, len(s.ShelfContent[shelfContent.ShelfID]))
+		for sIdx := range s.ShelfContent[shelfContent.ShelfID] {
+			if s.ShelfContent[shelfContent.ShelfID][sIdx].ContentID == shelfContent.ContentID {
+				continue
+			}
+
+			shelfContents = append(shelfContents, s.ShelfContent[shelfContent.ShelfID][sIdx])
+		}
+
+		s.ShelfContent[shelfContent.ShelfID] = shelfContents
+	}
+
+	for _, content := range entry.Contents {
+		if later := s.laterSyncContent(jIdx, content.ID); later != nil {
+			// A later sync changed this content again, hand over the previous value so undoing that sync restores it
+			later.Previous = content.Previous
+			continue
+		}
+
+		switch {
+		case content.Previous != nil:
+			s.ContentMap[content.ID] = *content.Previous
+
+		case !s.contentInUse(content.ID):
+			delete(s.ContentMap, content.ID)
+		}
+	}
+
+	s.Journal = append(s.Journal[:jIdx], s.Journal[jIdx+1:]...)
+
+	for _, device := range entry.Devices {
+		switch {
+		case device.Previous != nil:
+			s.DeviceMap[device.Device] = *device.Previous
+
+		case !s.deviceInUse(device.Device):
+			delete(s.DeviceMap, device.Device)
+		}
+	}
+
+	return entry, nil
+}
+
+// contentInUse is true when the content has events, bookmarks or shelf entries
+func (s *JSONStorage) contentInUse(fn string) bool {
+	if len(s.EventMap[fn]) > 0 || len(s.Bookmark[fn]) > 0 {
+		return true
+	}
+
+	for _, shelfContents := range s.ShelfContent {
+		for _, shelfContent := range shelfContents {
+			if shelfContent.ContentID == fn {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// deviceInUse is true when the device has events or syncs in the journal
+func (s *JSONStorage) deviceInUse(device string) bool {
+	for _, events := range s.EventMap {
+		for _, event := range events {
+			if event.Device == device {
+				return true
+			}
+		}
+	}
+
+	for _, entry := range s.Journal {
+		if entry.Device == device {
+			return true
+		}
+	}
+
+	return false
+}
+
+// journalDevice records the device in the current sync the first time it is added or changed
+func (s *JSONStorage) journalDevice(device string, previous StorageDevice, exists bool) {
+	for idx := range s.currentSync.Devices {
+		if s.currentSync.Devices[idx].Device == device {
+			return
+		}
+	}
+
+	entry := StorageSyncDevice{Device: device}
+	if exists {
+		entry.Previous = &previous
+	}
+
+	s.currentSync.Devices = append(s.currentSync.Devices, entry)
+}
+
+// journalContent records content in the current sync the first time it is added or changed
+func (s *JSONStorage) journalContent(fn string, previous StorageContent, exists bool) {
+	for idx := range s.currentSync.Contents {
+		if s.currentSync.Contents[idx].ID == fn {
+			return
+		}
+	}
+
+	entry := StorageSyncContent{ID: fn}
+	if exists {
+		entry.Previous = &previous
+	}
+
+	s.currentSync.Contents = append(s.currentSync.Contents, entry)
+}
+
+// laterSyncContent finds the first journal entry after jIdx that also added or changed the content, or added events,
+// bookmarks or shelf entries of it. Content that was unchanged when the later sync added them is recorded in that
+// entry so undoing it later still removes or restores the content
+func (s *JSONStorage) laterSyncContent(jIdx int, fn string) *StorageSyncContent {
+	for idx := jIdx + 1; idx < len(s.Journal); idx++ {
+		for cIdx := range s.Journal[idx].Contents {
+			if s.Journal[idx].Contents[cIdx].ID == fn {
+				return &s.Journal[idx].Contents[cIdx]
+			}
+		}
+
+		if s.Journal[idx].touches(fn) {
+			s.Journal[idx].Contents = append(s.Journal[idx].Contents, StorageSyncContent{ID: fn})
+			return &s.Journal[idx].Contents[len(s.Journal[idx].Contents)-1]
+		}
+	}
+
+	return nil
+}
+
+// touches is true when the sync added events, bookmarks or shelf entries of the content
+func (e StorageSync) touches(fn string) bool {
+	for _, event := range e.Events {
+		if event.ContentID == fn {
+			return true
+		}
+	}
+
+	for _, bookmark := range e.Bookmarks {
+		if bookmark.ContentID == fn {
+			return true
+		}
+	}
+
+	for _, shelfContent := range e.ShelfContents {
+		if shelfContent.ContentID == fn {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetCorrection replaces the correction of the content, a zero correction removes it
+func (s *JSONStorage) SetCorrection(cID string, correction StorageCorrection) {
+	if s.Correction == nil {
+		s.Correction = map[string]StorageCorrection{}
+	}
+
+	if correction.IsZero() {
+		delete(s.Correction, cID)
+		return
+	}
+
+	s.Correction[cID] = correction
+}
+
+func (s *JSONStorage) Corrections() map[string]StorageCorrection {
+	result := make(map[string]StorageCorrection, len(s.Correction))
+	for cID, correction := range s.Correction {
+		result[cID] = correction
+	}
+
+	return result
+}
@@ -0,0 +1,168 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageSyncJournal(t *testing.T) {
+	const (
+		testDevice = "test-device-a"
+		testBookID = "books/test-book-a.epub"
+	)
+
+	t.Run("undo removes added", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		storage.AddContent(testBookID, "Title", "Author", "", "", 100, true, false, 10)
+		storage.AddEvent(testBookID, testDevice, ReadEvent.String(), time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), 600)
+		storage.AddBookmark("bm", "vol", testBookID, "highlight", "", 0, 0, 0, "text", "", time.Time{}, time.Time{})
+		entry := storage.FinishSync()
+
+		assert.Equal(t, 1, entry.ID)
+		assert.Len(t, entry.Contents, 1)
+		assert.Nil(t, entry.Contents[0].Previous)
+		assert.Len(t, entry.Events, 1)
+		assert.Len(t, entry.Bookmarks, 1)
+		assert.Len(t, storage.Syncs(), 1)
+
+		undone, err := storage.UndoSync(entry.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entry.ID, undone.ID)
+
+		assert.Empty(t, storage.Contents())
+		assert.Empty(t, storage.Events(testBookID))
+		assert.Empty(t, storage.Bookmarks(testBookID))
+		assert.Empty(t, storage.Syncs())
+	})
+
+	t.Run("undo restores changed", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Now())
+		storage.AddContent(testBookID, "Title", "Author", "", "", 100, true, false, 10)
+		first := storage.FinishSync()
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "bbb", time.Now())
+		storage.AddContent(testBookID, "Bogus Title", "Author", "", "", 100, true, true, 100)
+		storage.AddContent("unchanged", "", "", "", "", 0, true, false, 0)
+		second := storage.FinishSync()
+
+		assert.Equal(t, 2, second.ID)
+		assert.Len(t, second.Contents, 2)
+		assert.Equal(t, "Title", second.Contents[0].Previous.Title)
+
+		_, err = storage.UndoSync(second.ID)
+		assert.NoError(t, err)
+
+		contents := storage.Contents()
+		assert.Len(t, contents, 1)
+		assert.Equal(t, "Title", contents[0].Title)
+		assert.False(t, contents[0].IsFinished)
+
+		_, err = storage.UndoSync(first.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, storage.Contents())
+
+		_, err = storage.UndoSync(first.ID)
+		assert.ErrorIs(t, err, ErrSyncNotFound)
+	})
+
+	t.Run("undo earlier keeps later changes", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Now())
+		storage.AddContent(testBookID, "Title", "Author", "", "", 100, true, false, 10)
+		first := storage.FinishSync()
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "bbb", time.Now())
+		storage.AddContent(testBookID, "Better Title", "Author", "", "", 100, true, false, 20)
+		second := storage.FinishSync()
+
+		_, err = storage.UndoSync(first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Better Title", storage.Contents()[0].Title)
+
+		_, err = storage.UndoSync(second.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, storage.Contents())
+	})
+
+	t.Run("undo earlier keeps content of later events", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Now())
+		storage.AddContent(testBookID, "Title", "Author", "", "", 100, true, false, 10)
+		first := storage.FinishSync()
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "bbb", time.Now())
+		storage.AddContent(testBookID, "Title", "Author", "", "", 100, true, false, 10)
+		storage.AddEvent(testBookID, testDevice, ReadEvent.String(), time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), 600)
+		second := storage.FinishSync()
+
+		assert.Empty(t, second.Contents)
+
+		_, err = storage.UndoSync(first.ID)
+		assert.NoError(t, err)
+		assert.Len(t, storage.Contents(), 1)
+		assert.Len(t, storage.Events(testBookID), 1)
+		assert.Len(t, NewStats(storage).Content, 1)
+
+		_, err = storage.UndoSync(second.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, storage.Contents())
+		assert.Empty(t, storage.Events(testBookID))
+	})
+
+	t.Run("undo keeps content still in use", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Now())
+		storage.AddContent(testBookID, "Title", "Author", "", "", 100, true, false, 10)
+		storage.AddContent("books/shelved.epub", "Shelved", "Author", "", "", 100, true, false, 0)
+		storage.AddShelfContent("Favourites", "books/shelved.epub", false)
+		entry := storage.FinishSync()
+
+		assert.Len(t, entry.ShelfContents, 1)
+
+		// A logged session is not part of the sync
+		storage.AddEvent(testBookID, ManualDevice, ReadEvent.String(), time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), 600)
+
+		_, err = storage.UndoSync(entry.ID)
+		assert.NoError(t, err)
+
+		contents := storage.Contents()
+		assert.Len(t, contents, 1)
+		assert.Equal(t, testBookID, contents[0].ID)
+		assert.Len(t, storage.Events(testBookID), 1)
+		assert.Empty(t, storage.ShelfContents("Favourites"))
+	})
+
+	t.Run("undo removes added device", func(t *testing.T) {
+		storage, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+		assert.NoError(t, err)
+
+		storage.AddDevice("test-device-b", "Clara 2E")
+
+		storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Now())
+		storage.AddDevice(testDevice, "Libra 2")
+		storage.AddDevice("test-device-b", "Clara BW")
+		entry := storage.FinishSync()
+
+		assert.Len(t, entry.Devices, 2)
+		assert.Nil(t, entry.Devices[0].Previous)
+
+		_, err = storage.UndoSync(entry.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []StorageDevice{{Device: "test-device-b", Model: "Clara 2E"}}, storage.Devices())
+	})
+}
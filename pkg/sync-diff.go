@@ -0,0 +1,160 @@
+package pkg
+
+import "sort"
+
+// SyncDiff describes what a sync changed, built from its journal entry
+type SyncDiff struct {
+	SyncID   int    `json:"sync_id"`
+	Device   string `json:"device"`
+	Database string `json:"database"`
+	Hash     string `json:"hash"`
+
+	NewBooks      []SyncDiffContent  `json:"new_books"`
+	NewArticles   []SyncDiffContent  `json:"new_articles"`
+	NewlyFinished []SyncDiffContent  `json:"newly_finished"`
+	ChangedTitles []SyncDiffTitle    `json:"changed_titles"`
+	AddedSessions []SyncDiffSession  `json:"added_sessions"`
+	NewBookmarks  []SyncDiffBookmark `json:"new_bookmarks"`
+}
+
+type SyncDiffContent struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	IsBook bool   `json:"is_book"`
+}
+
+type SyncDiffTitle struct {
+	ID             string `json:"id"`
+	PreviousTitle  string `json:"previous_title"`
+	Title          string `json:"title"`
+	PreviousAuthor string `json:"previous_author"`
+	Author         string `json:"author"`
+}
+
+type SyncDiffSession struct {
+	ContentID string `json:"content_id"`
+	Title     string `json:"title"`
+	Time      string `json:"time"`
+	Duration  int    `json:"duration"`
+	Device    string `json:"device"`
+}
+
+type SyncDiffBookmark struct {
+	ContentID string `json:"content_id"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+}
+
+// NewSyncDiff takes the storage after the sync and the sync journal entry and describes the changes
+func NewSyncDiff(storage Storage, entry StorageSync) SyncDiff {
+	result := SyncDiff{
+		SyncID:   entry.ID,
+		Device:   entry.Device,
+		Database: entry.Database,
+		Hash:     entry.Hash,
+
+		NewBooks:      make([]SyncDiffContent, 0),
+		NewArticles:   make([]SyncDiffContent, 0),
+		NewlyFinished: make([]SyncDiffContent, 0),
+		ChangedTitles: make([]SyncDiffTitle, 0),
+		AddedSessions: make([]SyncDiffSession, 0),
+		NewBookmarks:  make([]SyncDiffBookmark, 0),
+	}
+
+	contents := map[string]StorageContent{}
+	for _, content := range storage.Contents() {
+		contents[content.ID] = content
+	}
+
+	for _, changed := range entry.Contents {
+		content := contents[changed.ID]
+		diffContent := SyncDiffContent{
+			ID:     content.ID,
+			Title:  content.Title,
+			Author: content.Author,
+			IsBook: content.IsBook,
+		}
+
+		if changed.Previous == nil {
+			if content.IsBook {
+				result.NewBooks = append(result.NewBooks, diffContent)
+			} else {
+				result.NewArticles = append(result.NewArticles, diffContent)
+			}
+
+			if content.IsFinished {
+				result.NewlyFinished = append(result.NewlyFinished, diffContent)
+			}
+
+			continue
+		}
+
+		if content.IsFinished && !changed.Previous.IsFinished {
+			result.NewlyFinished = append(result.NewlyFinished, diffContent)
+		}
+
+		if content.Title != changed.Previous.Title || content.Author != changed.Previous.Author {
+			result.ChangedTitles = append(result.ChangedTitles, SyncDiffTitle{
+				ID:             content.ID,
+				PreviousTitle:  changed.Previous.Title,
+				Title:          content.Title,
+				PreviousAuthor: changed.Previous.Author,
+				Author:         content.Author,
+			})
+		}
+	}
+
+	for _, added := range entry.Events {
+		if added.EventName != ReadEvent.String() {
+			continue
+		}
+
+		for _, event := range storage.Events(added.ContentID) {
+			if event.EventName == added.EventName && event.Time == added.Time {
+				result.AddedSessions = append(result.AddedSessions, SyncDiffSession{
+					ContentID: added.ContentID,
+					Title:     contents[added.ContentID].Title,
+					Time:      event.Time,
+					Duration:  event.Duration,
+					Device:    event.Device,
+				})
+
+				break
+			}
+		}
+	}
+
+	sort.Slice(result.AddedSessions, func(i, j int) bool {
+		return result.AddedSessions[i].Time < result.AddedSessions[j].Time
+	})
+
+	for _, added := range entry.Bookmarks {
+		for _, bookmark := range storage.Bookmarks(added.ContentID) {
+			if bookmark.ID == added.ID && bookmark.Modified == added.Modified {
+				result.NewBookmarks = append(result.NewBookmarks, SyncDiffBookmark{
+					ContentID: added.ContentID,
+					Title:     contents[added.ContentID].Title,
+					Type:      bookmark.Type,
+					Text:      bookmark.Text,
+				})
+
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// SessionSeconds is the total duration of the added sessions
+func (d SyncDiff) SessionSeconds() int {
+	result := 0
+
+	for idx := range d.AddedSessions {
+		result += d.AddedSessions[idx].Duration
+	}
+
+	return result
+}
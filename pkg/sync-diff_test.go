@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSyncDiff(t *testing.T) {
+	const (
+		testDevice  = "test-device-a"
+		testBookAID = "books/test-book-a.epub"
+		testBookBID = "books/test-book-b.epub"
+	)
+
+	base, err := OpenStorageOrCreate(filepath.Join(t.TempDir(), "readstat.json"))
+	assert.NoError(t, err)
+
+	base.AddContent(testBookAID, "Old Title", "AAA", "", "", 100, true, false, 50)
+
+	storage := NewCopyOnWriteStorage(base)
+
+	storage.StartSync(testDevice, "KoboReader.sqlite", "aaa", time.Now())
+	storage.AddContent(testBookAID, "New Title", "AAA", "", "", 100, true, true, 100)
+	storage.AddContent(testBookBID, "Test Book B", "BBB", "", "", 100, true, false, 1)
+	storage.AddEvent(testBookAID, testDevice, ReadEvent.String(), time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC), 600)
+	storage.AddEvent(testBookAID, testDevice, FinishEvent.String(), time.Date(2024, 2, 1, 1, 10, 0, 0, time.UTC), 0)
+	storage.AddBookmark("bm", "vol", testBookAID, "highlight", "", 0, 0, 0, "text", "", time.Time{}, time.Time{})
+	entry := storage.FinishSync()
+
+	diff := NewSyncDiff(storage, entry)
+	assert.Len(t, diff.NewBooks, 1)
+	assert.Equal(t, testBookBID, diff.NewBooks[0].ID)
+	assert.Len(t, diff.NewlyFinished, 1)
+	assert.Equal(t, testBookAID, diff.NewlyFinished[0].ID)
+	assert.Len(t, diff.ChangedTitles, 1)
+	assert.Equal(t, "Old Title", diff.ChangedTitles[0].PreviousTitle)
+	assert.Len(t, diff.AddedSessions, 1)
+	assert.Equal(t, 600, diff.SessionSeconds())
+	assert.Len(t, diff.NewBookmarks, 1)
+
+	// The base storage is untouched
+	assert.Len(t, base.Contents(), 1)
+	assert.Equal(t, "Old Title", base.Contents()[0].Title)
+	assert.Empty(t, base.Events(testBookAID))
+	assert.Empty(t, base.Syncs())
+}
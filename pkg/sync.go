@@ -32,13 +32,21 @@ func Sync(db KoboDatabase, storage Storage) error {
 		return err
 	}
 
+	// Record everything this sync adds or changes in the storage journal
 	device, model := db.Device()
+	database, hash := db.Source()
+	storage.StartSync(device, database, hash, time.Now())
+
 	storage.AddDevice(device, model)
 
 	for cIdx := range contents {
 		storage.AddContent(contents[cIdx].ID, contents[cIdx].Title, contents[cIdx].Author,
-			contents[cIdx].URL, contents[cIdx].TotalWords(), contents[cIdx].IsBook,
+			contents[cIdx].URL, contents[cIdx].ISBN, contents[cIdx].TotalWords(), contents[cIdx].IsBook,
 			contents[cIdx].Finished, contents[cIdx].ProgressPercent)
+
+		if !contents[cIdx].LastFinished.IsZero() {
+			storage.AddEvent(contents[cIdx].ID, device, LastFinishedEvent.String(), contents[cIdx].LastFinished, 0)
+		}
 	}
 
 	for sIdx := range shelf {
@@ -91,5 +99,7 @@ func Sync(db KoboDatabase, storage Storage) error {
 		}
 	}
 
+	storage.FinishSync()
+
 	return nil
 }
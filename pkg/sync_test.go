@@ -28,9 +28,12 @@ func TestSync(t *testing.T) {
 		db.EXPECT().Contents()
 		db.EXPECT().Events()
 		db.EXPECT().Device().Return("aaa", "bbb")
+		db.EXPECT().Source().Return("ccc", "ddd")
 
 		storage := NewMockStorage(ctrl)
 		storage.EXPECT().AddDevice("aaa", "bbb")
+		storage.EXPECT().StartSync("aaa", "ccc", "ddd", gomock.Any())
+		storage.EXPECT().FinishSync()
 
 		err := Sync(db, storage)
 		assert.NoError(t, err)
@@ -53,7 +56,8 @@ func TestSync(t *testing.T) {
 						WordCount: 1234,
 					},
 				},
-				IsBook: true,
+				IsBook:       true,
+				LastFinished: time.Date(2003, 1, 2, 3, 4, 5, 0, time.UTC),
 			},
 		}
 
@@ -112,14 +116,18 @@ func TestSync(t *testing.T) {
 		db.EXPECT().Contents().Return(contents, nil)
 		db.EXPECT().Events().Return(aaaEvents, nil)
 		db.EXPECT().Device().Return("xxx", "yyy")
+		db.EXPECT().Source().Return("zzz", "hash")
 		db.EXPECT().Shelves().Return(aaaShelves, nil)
 		db.EXPECT().ShelfContents().Return(aaaShelfContent, nil)
 		db.EXPECT().Bookmarks().Return(aaaBookmarks, nil)
 
 		storage := NewMockStorage(ctrl)
-		storage.EXPECT().AddContent("aaa", "Title AAA", "Author AAA", "aaa.com", 1234, true, false, 69)
+		storage.EXPECT().AddContent("aaa", "Title AAA", "Author AAA", "aaa.com", "", 1234, true, false, 69)
 		storage.EXPECT().AddEvent("aaa", "xxx", "Read", time.Time{}, 1111)
+		storage.EXPECT().AddEvent("aaa", "xxx", "LastFinished", time.Date(2003, 1, 2, 3, 4, 5, 0, time.UTC), 0)
 		storage.EXPECT().AddDevice("xxx", "yyy")
+		storage.EXPECT().StartSync("xxx", "zzz", "hash", gomock.Any())
+		storage.EXPECT().FinishSync()
 		storage.EXPECT().AddShelf("AAA", "BBB", "CCC", "DDD", false)
 		storage.EXPECT().AddShelfContent("AAA", "aaa", false)
 		storage.EXPECT().AddBookmark("AA", "BB", "CC", "GG", "DD", 1, 2, 3, "EE", "FF",